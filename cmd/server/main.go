@@ -3,8 +3,12 @@ package main
 import (
 	"blog-system/config"
 	"blog-system/database"
+	"blog-system/jobs"
 	"blog-system/middleware"
 	"blog-system/routes"
+	"blog-system/spam"
+	"blog-system/storage"
+	"blog-system/utils"
 	"github.com/gin-gonic/gin"
 	"log"
 )
@@ -20,6 +24,27 @@ func main() {
 		log.Fatalf("数据库迁移失败: %v", err)
 	}
 
+	// 建立全文索引
+	if err := database.SetupSearch(database.DB); err != nil {
+		log.Fatalf("全文索引初始化失败: %v", err)
+	}
+
+	// 初始化文件存储
+	if err := storage.InitStorage(); err != nil {
+		log.Fatalf("文件存储初始化失败: %v", err)
+	}
+
+	// 初始化垃圾评论过滤器
+	spam.InitFilters()
+
+	// 启动后台任务
+	cfg := config.LoadConfig()
+	jobs.StartTrashPurger(cfg.TrashRetention)
+	jobs.StartViewRecorder(cfg.ViewDedupeWindow)
+
+	// 头像地址在读接口中频繁生成，启动时读取一次配置
+	utils.SetAvatarBaseURL(cfg.AvatarBaseURL)
+
 	// 创建Gin引擎实例
 	router := gin.Default()
 
@@ -30,7 +55,6 @@ func main() {
 	routes.InitRoutes(router)
 
 	// 启动服务器
-	cfg := config.LoadConfig()
 	addr := ":" + cfg.ServerPort
 	log.Printf("服务器启动，监听地址: http://localhost%s", addr)
 	if err := router.Run(addr); err != nil {
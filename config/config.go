@@ -17,6 +17,31 @@ type Config struct {
 	DBMaxOpenConns    int
 	DBMaxIdleConns    int
 	DBConnMaxLifetime time.Duration
+	TrashRetention    time.Duration // 回收站保留时长，为0时不自动清理
+	CacheControl      string        // 读接口的 Cache-Control 响应头
+	StorageDriver     string        // 文件存储后端
+	UploadDir         string        // 本地存储的上传目录
+	UploadBaseURL     string        // 上传文件的访问URL前缀
+	UploadMaxSize     int64         // 单个上传文件的最大字节数
+	PostAccessTTL     time.Duration // 受密码保护文章访问授权的有效期
+	ViewDedupeWindow  time.Duration // 同一访客重复浏览同一文章不重复计数的时间窗口
+	CommentMaxDepth   int           // 评论回复的最大嵌套层数，为0时不允许回复
+	CommentEditWindow time.Duration // 评论发布后允许编辑的时长，为0时不限制
+	CommentModeration string        // 站点默认的评论审核模式：off、first_time 或 all
+	CommentAutoClose  time.Duration // 文章发布后自动关闭评论的时长，为0时不自动关闭
+	SpamModerateScore float64       // 垃圾评论得分达到该值时转入人工审核
+	SpamRejectScore   float64       // 垃圾评论得分达到该值时直接拒绝
+	SpamMaxLinks      int           // 评论中允许的最大链接数
+	SpamKeywords      string        // 垃圾评论关键词，逗号分隔
+	SpamDomains       string        // 禁止出现的链接域名，逗号分隔
+	SpamMinSubmitTime time.Duration // 从打开页面到提交评论的最短时间
+	SpamBayesMinDocs  int64         // 分类器生效所需的最少垃圾/正常评论训练样本数
+	AvatarBaseURL     string        // Gravatar 兼容的头像服务地址
+	ReportHideCount   int           // 内容收到该数量的待处理举报后自动隐藏，为0时不自动隐藏
+	GuestPowBits      int           // 游客评论工作量证明要求的哈希前导零比特数
+	GuestChallengeTTL time.Duration // 游客评论验证题目的有效期
+	UnlockIPFailures  int           // 同一IP在限制窗口内允许的文章密码错误次数，为0时不限制
+	UnlockWindow      time.Duration // 文章密码错误次数的统计窗口，超过次数后在窗口结束前拒绝尝试
 }
 
 // LoadConfig 加载配置
@@ -31,6 +56,7 @@ func LoadConfig() Config {
 	maxOpenConns := getEnvAsInt("DB_MAX_OPEN_CONNS", 25)
 	maxIdleConns := getEnvAsInt("DB_MAX_IDLE_CONNS", 5)
 	connMaxLifetime := time.Duration(getEnvAsInt("DB_CONN_MAX_LIFETIME", 30)) * time.Minute
+	trashRetention := time.Duration(getEnvAsInt("TRASH_RETENTION_DAYS", 30)) * 24 * time.Hour
 
 	return Config{
 		DBDriver:          getEnv("DB_DRIVER", "mysql"),
@@ -40,6 +66,31 @@ func LoadConfig() Config {
 		DBMaxOpenConns:    maxOpenConns,
 		DBMaxIdleConns:    maxIdleConns,
 		DBConnMaxLifetime: connMaxLifetime,
+		TrashRetention:    trashRetention,
+		CacheControl:      getEnv("CACHE_CONTROL", "public, max-age=0, must-revalidate"),
+		StorageDriver:     getEnv("STORAGE_DRIVER", "local"),
+		UploadDir:         getEnv("UPLOAD_DIR", "uploads"),
+		UploadBaseURL:     getEnv("UPLOAD_BASE_URL", "/uploads"),
+		UploadMaxSize:     int64(getEnvAsInt("UPLOAD_MAX_SIZE_MB", 10)) << 20,
+		PostAccessTTL:     time.Duration(getEnvAsInt("POST_ACCESS_TTL_MINUTES", 30)) * time.Minute,
+		ViewDedupeWindow:  time.Duration(getEnvAsInt("VIEW_DEDUPE_MINUTES", 30)) * time.Minute,
+		CommentMaxDepth:   getEnvAsInt("COMMENT_MAX_DEPTH", 5),
+		CommentEditWindow: time.Duration(getEnvAsInt("COMMENT_EDIT_WINDOW_MINUTES", 15)) * time.Minute,
+		CommentModeration: getEnv("COMMENT_MODERATION", "off"),
+		CommentAutoClose:  time.Duration(getEnvAsInt("COMMENT_AUTO_CLOSE_DAYS", 0)) * 24 * time.Hour,
+		SpamModerateScore: getEnvAsFloat("SPAM_MODERATE_SCORE", 1),
+		SpamRejectScore:   getEnvAsFloat("SPAM_REJECT_SCORE", 3),
+		SpamMaxLinks:      getEnvAsInt("SPAM_MAX_LINKS", 2),
+		SpamKeywords:      getEnv("SPAM_KEYWORDS", ""),
+		SpamDomains:       getEnv("SPAM_BLOCKED_DOMAINS", ""),
+		SpamMinSubmitTime: time.Duration(getEnvAsInt("SPAM_MIN_SUBMIT_SECONDS", 3)) * time.Second,
+		SpamBayesMinDocs:  int64(getEnvAsInt("SPAM_BAYES_MIN_DOCS", 10)),
+		AvatarBaseURL:     getEnv("AVATAR_BASE_URL", "https://www.gravatar.com/avatar"),
+		ReportHideCount:   getEnvAsInt("REPORT_HIDE_THRESHOLD", 3),
+		GuestPowBits:      getEnvAsInt("GUEST_POW_DIFFICULTY", 18),
+		GuestChallengeTTL: time.Duration(getEnvAsInt("GUEST_CHALLENGE_TTL_MINUTES", 10)) * time.Minute,
+		UnlockIPFailures:  getEnvAsInt("UNLOCK_MAX_FAILURES_PER_IP", 10),
+		UnlockWindow:      time.Duration(getEnvAsInt("UNLOCK_WINDOW_MINUTES", 15)) * time.Minute,
 	}
 }
 
@@ -66,3 +117,18 @@ func getEnvAsInt(key string, defaultValue int) int {
 	}
 	return intValue
 }
+
+// getEnvAsFloat 获取浮点型环境变量
+func getEnvAsFloat(key string, defaultValue float64) float64 {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+
+	floatValue, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		log.Printf("警告: 环境变量 %s 的值 '%s' 不是有效数字，使用默认值 %g", key, value, defaultValue)
+		return defaultValue
+	}
+	return floatValue
+}
@@ -20,6 +20,8 @@ func Register(c *gin.Context) {
 		log.Fatalf("绑定用户错误: %v", err)
 		return
 	}
+	// 注册用户一律为普通角色，防止通过请求体自行提升权限
+	user.Role = models.RoleUser
 	// 密码加密
 	password, err := utils.HashPassword(user.Password)
 	if err != nil {
@@ -76,6 +78,10 @@ func Login(c *gin.Context) {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "用户名或密码错误"})
 		return
 	}
+	if user.SuspendedAt != nil {
+		c.JSON(http.StatusForbidden, gin.H{"error": "账号已被停用"})
+		return
+	}
 
 	cfg := config.LoadConfig()
 	jwtSecret := cfg.JWTSecret
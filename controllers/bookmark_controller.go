@@ -0,0 +1,475 @@
+package controllers
+
+import (
+	"blog-system/database"
+	"blog-system/models"
+	"blog-system/utils"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// errFolderExists 同名收藏夹已存在
+var errFolderExists = errors.New("同名收藏夹已存在")
+
+// bookmarkedPost 收藏列表中的文章摘要
+type bookmarkedPost struct {
+	ID          uint       `json:"id"`
+	Title       string     `json:"title"`
+	Teaser      string     `json:"teaser"`
+	UserID      uint       `json:"user_id"`
+	PublishedAt *time.Time `json:"published_at"`
+	Locked      bool       `json:"locked,omitempty"`
+}
+
+// bookmarkItem 收藏列表项
+// 文章已删除、撤回为草稿或不再对当前用户可见时 available 为 false，且不返回文章内容
+type bookmarkItem struct {
+	models.Bookmark
+	Available bool            `json:"available"`
+	Post      *bookmarkedPost `json:"post"`
+}
+
+// folderWithCount 带收藏数量的收藏夹
+type folderWithCount struct {
+	models.BookmarkFolder
+	BookmarkCount int64 `json:"bookmark_count"`
+}
+
+// GetBookmarks 获取当前用户的收藏
+// 查询参数: folder_id - 可选，只返回该收藏夹中的收藏（0 表示未分组）, page/page_size - 分页
+// 参数: c - Gin上下文
+func GetBookmarks(c *gin.Context) {
+	userId, ok := currentUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+	page, pageSize := parsePagination(c)
+
+	query := database.DB.Model(&models.Bookmark{}).Where("user_id = ?", userId)
+	if raw := c.Query("folder_id"); raw != "" {
+		folderID, err := strconv.ParseUint(raw, 10, 32)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "无效的收藏夹ID"})
+			return
+		}
+		if folderID == 0 {
+			query = query.Where("folder_id IS NULL")
+		} else {
+			query = query.Where("folder_id = ?", folderID)
+		}
+	}
+
+	var total int64
+	if err := query.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "获取收藏失败"})
+		return
+	}
+
+	var bookmarks []models.Bookmark
+	if err := query.Order("created_at DESC, id DESC").Offset((page - 1) * pageSize).Limit(pageSize).Find(&bookmarks).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "获取收藏失败"})
+		return
+	}
+
+	// 包含回收站中的文章，以便标记为不可用而不是直接丢弃
+	postIDs := make([]uint, len(bookmarks))
+	for i, bookmark := range bookmarks {
+		postIDs[i] = bookmark.PostID
+	}
+	var posts []models.Post
+	if err := database.DB.Unscoped().Where("id IN ?", postIDs).Find(&posts).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "获取收藏失败"})
+		return
+	}
+	postByID := make(map[uint]*models.Post, len(posts))
+	for i := range posts {
+		postByID[posts[i].ID] = &posts[i]
+	}
+
+	items := make([]bookmarkItem, 0, len(bookmarks))
+	for _, bookmark := range bookmarks {
+		item := bookmarkItem{Bookmark: bookmark}
+		if post, ok := postByID[bookmark.PostID]; ok && !post.DeletedAt.Valid {
+			access, err := postAccess(c, post)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "获取收藏失败"})
+				return
+			}
+			if access != postAccessNone {
+				ensureRendered(post)
+				item.Available = true
+				item.Post = &bookmarkedPost{
+					ID:          post.ID,
+					Title:       post.Title,
+					Teaser:      utils.Teaser(post.ContentHTML, teaserWidth),
+					UserID:      post.UserID,
+					PublishedAt: post.PublishedAt,
+					Locked:      access == postAccessTeaser,
+				}
+			}
+		}
+		items = append(items, item)
+	}
+
+	c.JSON(http.StatusOK, gin.H{"total": total, "page": page, "page_size": pageSize, "items": items})
+}
+
+// AddBookmark 收藏文章，已收藏时移动到指定收藏夹
+// 请求体（可选）: folder_id - 收藏夹ID，为空或 null 表示未分组
+// 参数: c - Gin上下文
+func AddBookmark(c *gin.Context) {
+	userId, ok := currentUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+	postID, err := strconv.Atoi(c.Param("post_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的文章ID"})
+		return
+	}
+
+	var folderID *uint
+	if c.Request.ContentLength != 0 {
+		var data map[string]interface{}
+		if err := c.ShouldBindJSON(&data); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if folderID, err = parseOptionalID(data["folder_id"]); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "folder_id " + err.Error()})
+			return
+		}
+	}
+	if folderID != nil {
+		var count int64
+		if err := database.DB.Model(&models.BookmarkFolder{}).Where("id = ? AND user_id = ?", *folderID, userId).Count(&count).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+			return
+		}
+		if count == 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "收藏夹不存在"})
+			return
+		}
+	}
+
+	// 受密码保护的文章未解锁时也可以收藏
+	var post models.Post
+	if err := database.DB.Select("id", "user_id", "status", "visibility", "password_hash", "hidden_at").First(&post, postID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "文章不存在"})
+		return
+	}
+	if access, err := postAccess(c, &post); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	} else if access == postAccessNone {
+		c.JSON(http.StatusNotFound, gin.H{"error": "文章不存在"})
+		return
+	}
+
+	now := time.Now()
+	bookmark := models.Bookmark{UserID: userId, PostID: post.ID, FolderID: folderID, CreatedAt: now, UpdatedAt: now}
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&bookmark)
+		if result.Error != nil || result.RowsAffected > 0 {
+			return result.Error
+		}
+		// 已有记录：移动收藏夹，已取消的收藏被恢复并视为重新收藏
+		// Updates 按列名排序生成 SET，created_at 会在 deleted_at 之前赋值
+		return tx.Unscoped().Model(&models.Bookmark{}).
+			Where("user_id = ? AND post_id = ?", userId, post.ID).
+			Updates(map[string]interface{}{
+				"created_at": gorm.Expr("CASE WHEN deleted_at IS NULL THEN created_at ELSE ? END", now),
+				"deleted_at": nil,
+				"folder_id":  folderID,
+				"updated_at": now,
+			}).Error
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "收藏失败"})
+		return
+	}
+
+	if err := database.DB.Where("user_id = ? AND post_id = ?", userId, post.ID).First(&bookmark).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "收藏失败"})
+		return
+	}
+	c.JSON(http.StatusOK, bookmark)
+}
+
+// RemoveBookmark 取消收藏文章
+// 参数: c - Gin上下文
+func RemoveBookmark(c *gin.Context) {
+	userId, ok := currentUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+	postID, err := strconv.Atoi(c.Param("post_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的文章ID"})
+		return
+	}
+
+	result := database.DB.Where("user_id = ? AND post_id = ?", userId, postID).Delete(&models.Bookmark{})
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "取消收藏失败"})
+		return
+	}
+	if result.RowsAffected == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "未收藏该文章"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "已取消收藏"})
+}
+
+// GetBookmarkFolders 获取当前用户的收藏夹及各收藏夹中的收藏数
+// 参数: c - Gin上下文
+func GetBookmarkFolders(c *gin.Context) {
+	userId, ok := currentUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+
+	var folders []models.BookmarkFolder
+	if err := database.DB.Where("user_id = ?", userId).Order("name ASC").Find(&folders).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "获取收藏夹失败"})
+		return
+	}
+
+	var counts []struct {
+		FolderID *uint
+		Count    int64
+	}
+	err := database.DB.Model(&models.Bookmark{}).
+		Select("folder_id, COUNT(*) AS count").
+		Where("user_id = ?", userId).
+		Group("folder_id").
+		Scan(&counts).Error
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "获取收藏夹失败"})
+		return
+	}
+	var unfiled int64
+	countByID := make(map[uint]int64, len(counts))
+	for _, row := range counts {
+		if row.FolderID == nil {
+			unfiled = row.Count
+		} else {
+			countByID[*row.FolderID] = row.Count
+		}
+	}
+
+	items := make([]folderWithCount, len(folders))
+	for i, folder := range folders {
+		items[i] = folderWithCount{BookmarkFolder: folder, BookmarkCount: countByID[folder.ID]}
+	}
+
+	c.JSON(http.StatusOK, gin.H{"folders": items, "unfiled_count": unfiled})
+}
+
+// CreateBookmarkFolder 创建收藏夹
+// 请求体: name - 收藏夹名称
+// 参数: c - Gin上下文
+func CreateBookmarkFolder(c *gin.Context) {
+	userId, ok := currentUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+
+	var data map[string]interface{}
+	if err := c.ShouldBindJSON(&data); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	name, err := parseFolderName(data["name"])
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	folder := models.BookmarkFolder{UserID: userId, Name: name}
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := checkFolderName(tx, userId, name, 0); err != nil {
+			return err
+		}
+		return tx.Create(&folder).Error
+	})
+	if err != nil {
+		respondFolderError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, folder)
+}
+
+// UpdateBookmarkFolder 重命名收藏夹
+// 请求体: name - 新名称
+// 参数: c - Gin上下文
+func UpdateBookmarkFolder(c *gin.Context) {
+	folder, ok := loadOwnedFolder(c)
+	if !ok {
+		return
+	}
+
+	var data map[string]interface{}
+	if err := c.ShouldBindJSON(&data); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	name, err := parseFolderName(data["name"])
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := checkFolderName(tx, folder.UserID, name, folder.ID); err != nil {
+			return err
+		}
+		return tx.Model(folder).Update("name", name).Error
+	})
+	if err != nil {
+		respondFolderError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, folder)
+}
+
+// DeleteBookmarkFolder 删除收藏夹，其中的收藏变为未分组
+// 参数: c - Gin上下文
+func DeleteBookmarkFolder(c *gin.Context) {
+	folder, ok := loadOwnedFolder(c)
+	if !ok {
+		return
+	}
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Model(&models.Bookmark{}).Where("folder_id = ?", folder.ID).
+			Updates(map[string]interface{}{"folder_id": nil, "updated_at": time.Now()}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(folder).Error
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "删除收藏夹失败"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "收藏夹删除成功"})
+}
+
+// loadOwnedFolder 加载当前用户的收藏夹，失败时直接写入错误响应
+// 参数: c - Gin上下文
+// 返回值: 收藏夹, 是否成功
+func loadOwnedFolder(c *gin.Context) (*models.BookmarkFolder, bool) {
+	userId, ok := currentUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return nil, false
+	}
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的收藏夹ID"})
+		return nil, false
+	}
+
+	// 收藏夹只对所有者可见，其他用户的收藏夹按不存在处理
+	var folder models.BookmarkFolder
+	if err := database.DB.Where("id = ? AND user_id = ?", id, userId).First(&folder).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "收藏夹不存在"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		}
+		return nil, false
+	}
+	return &folder, true
+}
+
+// parseFolderName 解析收藏夹名称
+func parseFolderName(raw interface{}) (string, error) {
+	name, ok := raw.(string)
+	name = strings.TrimSpace(name)
+	if !ok || name == "" || len(name) > 64 {
+		return "", invalidInput("name must be a non-empty string of at most 64 characters")
+	}
+	return name, nil
+}
+
+// checkFolderName 检查同一用户下是否已有同名收藏夹
+// 参数: tx - 数据库事务, userID - 用户ID, name - 名称, excludeID - 排除的收藏夹ID（重命名时为自身）
+func checkFolderName(tx *gorm.DB, userID uint, name string, excludeID uint) error {
+	var count int64
+	if err := tx.Model(&models.BookmarkFolder{}).Where("user_id = ? AND name = ? AND id <> ?", userID, name, excludeID).
+		Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return errFolderExists
+	}
+	return nil
+}
+
+// respondFolderError 将收藏夹操作的错误映射为HTTP响应
+func respondFolderError(c *gin.Context, err error) {
+	switch {
+	case isInputError(err):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, errFolderExists):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "保存收藏夹失败"})
+	}
+}
+
+// fillBookmarked 为文章标记当前用户是否已收藏
+// 参数: posts - 文章列表, userID - 当前用户ID（0 表示未登录，此时不做标记）
+func fillBookmarked(posts []*models.Post, userID uint) error {
+	if userID == 0 || len(posts) == 0 {
+		return nil
+	}
+	ids := make([]uint, len(posts))
+	for i, post := range posts {
+		ids[i] = post.ID
+	}
+
+	var bookmarked []uint
+	if err := database.DB.Model(&models.Bookmark{}).Where("user_id = ? AND post_id IN ?", userID, ids).
+		Pluck("post_id", &bookmarked).Error; err != nil {
+		return err
+	}
+	set := make(map[uint]bool, len(bookmarked))
+	for _, id := range bookmarked {
+		set[id] = true
+	}
+	for _, post := range posts {
+		flag := set[post.ID]
+		post.Bookmarked = &flag
+	}
+	return nil
+}
+
+// lastBookmarkChange 查询当前用户对这些文章的收藏最近一次变化的时间
+// 参数: posts - 文章列表, userID - 当前用户ID（0 表示未登录）
+func lastBookmarkChange(posts []*models.Post, userID uint) (time.Time, error) {
+	if userID == 0 || len(posts) == 0 {
+		return time.Time{}, nil
+	}
+	ids := make([]uint, len(posts))
+	for i, post := range posts {
+		ids[i] = post.ID
+	}
+	return lastChange(&models.Bookmark{}, "user_id = ? AND post_id IN ?", userID, ids)
+}
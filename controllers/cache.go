@@ -0,0 +1,109 @@
+package controllers
+
+import (
+	"blog-system/config"
+	"blog-system/database"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// respondCached 返回支持条件请求的 JSON 响应
+// ETag 由响应体哈希生成（可带前缀），Last-Modified 取资源的最后修改时间；
+// 命中 If-None-Match 或 If-Modified-Since 时返回 304 且不带响应体
+// 参数: c - Gin上下文, etagPrefix - ETag 前缀（可为空）, lastModified - 最后修改时间, body - 响应数据
+func respondCached(c *gin.Context, etagPrefix string, lastModified time.Time, body interface{}) {
+	data, err := json.Marshal(body)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "序列化响应失败"})
+		return
+	}
+
+	sum := sha256.Sum256(data)
+	tag := hex.EncodeToString(sum[:8])
+	if etagPrefix != "" {
+		tag = etagPrefix + "-" + tag
+	}
+	etag := `"` + tag + `"`
+
+	c.Header("ETag", etag)
+	// 调用方已设置缓存策略（如草稿不允许共享缓存）时不覆盖
+	if c.Writer.Header().Get("Cache-Control") == "" {
+		c.Header("Cache-Control", config.LoadConfig().CacheControl)
+	}
+	if !lastModified.IsZero() {
+		c.Header("Last-Modified", lastModified.UTC().Format(http.TimeFormat))
+	}
+
+	if notModified(c.Request, etag, lastModified) {
+		c.Status(http.StatusNotModified)
+		return
+	}
+
+	c.Data(http.StatusOK, "application/json; charset=utf-8", data)
+}
+
+// notModified 判断条件请求是否命中缓存
+// If-None-Match 优先于 If-Modified-Since，存在时后者被忽略
+// 参数: r - HTTP请求, etag - 当前 ETag, lastModified - 最后修改时间
+func notModified(r *http.Request, etag string, lastModified time.Time) bool {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		return false
+	}
+
+	if header := r.Header.Get("If-None-Match"); header != "" {
+		for _, candidate := range strings.Split(header, ",") {
+			candidate = strings.TrimSpace(candidate)
+			// If-None-Match 使用弱比较
+			if candidate == "*" || strings.TrimPrefix(candidate, "W/") == etag {
+				return true
+			}
+		}
+		return false
+	}
+
+	if header := r.Header.Get("If-Modified-Since"); header != "" && !lastModified.IsZero() {
+		since, err := http.ParseTime(header)
+		// HTTP 日期只精确到秒
+		return err == nil && !lastModified.Truncate(time.Second).After(since)
+	}
+	return false
+}
+
+// latestTime 返回多个时间中最晚的一个
+func latestTime(times ...time.Time) time.Time {
+	var latest time.Time
+	for _, t := range times {
+		if t.After(latest) {
+			latest = t
+		}
+	}
+	return latest
+}
+
+// lastChange 查询表中最近一次修改或软删除的时间，用作 Last-Modified
+// 参数: model - 模型, where/args - 额外过滤条件（可为空字符串）
+// 返回值: 最近修改时间，无记录时为零值
+func lastChange(model interface{}, where string, args ...interface{}) (time.Time, error) {
+	var latest time.Time
+	for _, column := range []string{"updated_at", "deleted_at"} {
+		query := database.DB.Unscoped().Model(model).Where(column + " IS NOT NULL")
+		if where != "" {
+			query = query.Where(where, args...)
+		}
+
+		// 直接取列值而不是 MAX()，以便 SQLite 驱动按列类型解析时间
+		var times []time.Time
+		if err := query.Order(column+" DESC").Limit(1).Pluck(column, &times).Error; err != nil {
+			return time.Time{}, err
+		}
+		if len(times) > 0 {
+			latest = latestTime(latest, times[0])
+		}
+	}
+	return latest, nil
+}
@@ -0,0 +1,429 @@
+package controllers
+
+import (
+	"blog-system/database"
+	"blog-system/models"
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// errCategoryConflict 分类操作与现有数据冲突
+var errCategoryConflict = errors.New("category conflict")
+
+// GetCategories 获取分类树
+// 参数: c - Gin上下文
+func GetCategories(c *gin.Context) {
+	var categories []models.Category
+	if err := database.DB.Find(&categories).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "获取分类列表失败"})
+		return
+	}
+
+	c.JSON(http.StatusOK, buildCategoryTree(categories))
+}
+
+// GetCategory 获取单个分类及其子分类和祖先路径
+// 参数: c - Gin上下文
+func GetCategory(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的分类ID"})
+		return
+	}
+
+	var categories []models.Category
+	if err := database.DB.Find(&categories).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "获取分类失败"})
+		return
+	}
+
+	var found *models.Category
+	for _, node := range buildCategoryIndex(buildCategoryTree(categories)) {
+		if node.ID == uint(id) {
+			found = node
+			break
+		}
+	}
+	if found == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "分类不存在"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"category": found,
+		"path":     categoryPath(categories, found.ID),
+	})
+}
+
+// CreateCategory 创建分类（管理员）
+// 参数: c - Gin上下文
+func CreateCategory(c *gin.Context) {
+	var data map[string]interface{}
+	if err := c.ShouldBindJSON(&data); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var category models.Category
+	name, ok := data["name"].(string)
+	category.Name = strings.TrimSpace(name)
+	if !ok || category.Name == "" || len(category.Name) > 64 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "name must be a non-empty string of at most 64 characters"})
+		return
+	}
+
+	parentID, err := parseOptionalID(data["parent_id"])
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "parent_id " + err.Error()})
+		return
+	}
+	category.ParentID = parentID
+
+	if sortValue, ok := data["sort"].(float64); ok {
+		category.Sort = int(sortValue)
+	} else if data["sort"] != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "sort must be a number"})
+		return
+	}
+
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		if category.ParentID != nil {
+			if err := tx.First(&models.Category{}, *category.ParentID).Error; err != nil {
+				return err
+			}
+		}
+		if err := checkSiblingName(tx, category.Name, category.ParentID, 0); err != nil {
+			return err
+		}
+		return tx.Create(&category).Error
+	})
+	if err != nil {
+		respondCategoryError(c, err, "创建分类失败")
+		return
+	}
+
+	c.JSON(http.StatusCreated, category)
+}
+
+// UpdateCategory 更新分类（管理员），支持重命名、移动和调整排序
+// 参数: c - Gin上下文
+func UpdateCategory(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的分类ID"})
+		return
+	}
+
+	var data map[string]interface{}
+	if err := c.ShouldBindJSON(&data); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var category models.Category
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.First(&category, id).Error; err != nil {
+			return err
+		}
+
+		updateData := make(map[string]interface{})
+		if name, ok := data["name"].(string); ok {
+			name = strings.TrimSpace(name)
+			if name == "" || len(name) > 64 {
+				return invalidInput("name must be a non-empty string of at most 64 characters")
+			}
+			category.Name = name
+			updateData["name"] = name
+		} else if data["name"] != nil {
+			return invalidInput("name must be a string")
+		}
+
+		// parent_id 显式传 null 表示移动到顶级
+		if raw, ok := data["parent_id"]; ok {
+			parentID, err := parseOptionalID(raw)
+			if err != nil {
+				return invalidInput("parent_id %s", err.Error())
+			}
+			if parentID != nil {
+				var categories []models.Category
+				if err := tx.Find(&categories).Error; err != nil {
+					return err
+				}
+				if !categoryExists(categories, *parentID) {
+					return gorm.ErrRecordNotFound
+				}
+				// 不能移动到自身或自身的子孙分类下，避免形成环
+				for _, descendant := range descendantCategoryIDs(categories, category.ID) {
+					if descendant == *parentID {
+						return fmt.Errorf("%w: cannot move a category under itself or its descendants", errCategoryConflict)
+					}
+				}
+			}
+			category.ParentID = parentID
+			updateData["parent_id"] = parentID
+		}
+
+		if sortValue, ok := data["sort"].(float64); ok {
+			category.Sort = int(sortValue)
+			updateData["sort"] = category.Sort
+		} else if data["sort"] != nil {
+			return invalidInput("sort must be a number")
+		}
+
+		if len(updateData) == 0 {
+			return invalidInput("没有提供有效的更新字段")
+		}
+
+		if err := checkSiblingName(tx, category.Name, category.ParentID, category.ID); err != nil {
+			return err
+		}
+		return tx.Model(&category).Updates(updateData).Error
+	})
+	if err != nil {
+		respondCategoryError(c, err, "更新分类失败")
+		return
+	}
+
+	c.JSON(http.StatusOK, category)
+}
+
+// DeleteCategory 删除分类（管理员）
+// 子分类上移到被删除分类的父分类下（与新的同级分类重名时返回冲突）；文章转移到 move_to 指定的分类，未指定时转移到父分类
+// 参数: c - Gin上下文
+func DeleteCategory(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的分类ID"})
+		return
+	}
+
+	var moveTo *uint
+	if raw := c.Query("move_to"); raw != "" {
+		target, err := strconv.ParseUint(raw, 10, 32)
+		if err != nil || target == 0 || target == uint64(id) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "无效的目标分类ID"})
+			return
+		}
+		value := uint(target)
+		moveTo = &value
+	}
+
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		var category models.Category
+		if err := tx.First(&category, id).Error; err != nil {
+			return err
+		}
+
+		target := category.ParentID
+		if moveTo != nil {
+			var categories []models.Category
+			if err := tx.Find(&categories).Error; err != nil {
+				return err
+			}
+			if !categoryExists(categories, *moveTo) {
+				return gorm.ErrRecordNotFound
+			}
+			// 目标可以是子孙分类，它们会随子分类一起上移
+			target = moveTo
+		}
+
+		var postCount int64
+		if err := tx.Unscoped().Model(&models.Post{}).Where("category_id = ?", category.ID).Count(&postCount).Error; err != nil {
+			return err
+		}
+		if postCount > 0 && target == nil {
+			return fmt.Errorf("%w: top-level category still has posts, specify move_to", errCategoryConflict)
+		}
+
+		// 转移文章（包括回收站中的文章，避免恢复后指向已删除的分类）
+		if postCount > 0 {
+			if err := tx.Unscoped().Model(&models.Post{}).Where("category_id = ?", category.ID).
+				UpdateColumn("category_id", target).Error; err != nil {
+				return err
+			}
+		}
+
+		// 先删除分类，子分类与它同名时不算冲突
+		if err := tx.Delete(&category).Error; err != nil {
+			return err
+		}
+
+		// 子分类上移一级，与新的同级分类重名时拒绝删除
+		var children []models.Category
+		if err := tx.Where("parent_id = ?", category.ID).Find(&children).Error; err != nil {
+			return err
+		}
+		for _, child := range children {
+			if err := checkSiblingName(tx, child.Name, category.ParentID, child.ID); err != nil {
+				return err
+			}
+		}
+		if len(children) == 0 {
+			return nil
+		}
+		return tx.Model(&models.Category{}).Where("parent_id = ?", category.ID).
+			Update("parent_id", category.ParentID).Error
+	})
+	if err != nil {
+		respondCategoryError(c, err, "删除分类失败")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "分类删除成功"})
+}
+
+// respondCategoryError 将分类操作错误映射为HTTP响应
+// 参数: c - Gin上下文, err - 错误, fallback - 默认错误信息
+func respondCategoryError(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "分类不存在"})
+	case isInputError(err):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, errCategoryConflict):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fallback})
+	}
+}
+
+// checkSiblingName 检查同级分类下是否已存在同名分类
+// 参数: tx - 数据库实例, name - 分类名称, parentID - 父分类ID, excludeID - 排除的分类ID
+func checkSiblingName(tx *gorm.DB, name string, parentID *uint, excludeID uint) error {
+	query := tx.Model(&models.Category{}).Where("name = ? AND id <> ?", name, excludeID)
+	if parentID == nil {
+		query = query.Where("parent_id IS NULL")
+	} else {
+		query = query.Where("parent_id = ?", *parentID)
+	}
+
+	var count int64
+	if err := query.Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return fmt.Errorf("%w: category %q already exists at this level", errCategoryConflict, name)
+	}
+	return nil
+}
+
+// parseOptionalID 解析可为空的ID字段
+// 参数: raw - JSON 中的数值或 null
+// 返回值: ID指针（null 时为 nil）, 错误信息
+func parseOptionalID(raw interface{}) (*uint, error) {
+	switch v := raw.(type) {
+	case nil:
+		return nil, nil
+	case float64:
+		if v <= 0 || v != float64(uint(v)) {
+			return nil, fmt.Errorf("must be a positive integer")
+		}
+		id := uint(v)
+		return &id, nil
+	default:
+		return nil, fmt.Errorf("must be a number or null")
+	}
+}
+
+// buildCategoryTree 将平铺的分类列表组装为树
+// 参数: categories - 全部分类
+// 返回值: 顶级分类列表（含子分类）
+func buildCategoryTree(categories []models.Category) []models.Category {
+	children := make(map[uint][]models.Category)
+	var roots []models.Category
+	for _, category := range categories {
+		category.Children = nil
+		if category.ParentID == nil {
+			roots = append(roots, category)
+		} else {
+			children[*category.ParentID] = append(children[*category.ParentID], category)
+		}
+	}
+
+	var attach func(nodes []models.Category) []models.Category
+	attach = func(nodes []models.Category) []models.Category {
+		sort.SliceStable(nodes, func(i, j int) bool {
+			if nodes[i].Sort != nodes[j].Sort {
+				return nodes[i].Sort < nodes[j].Sort
+			}
+			return nodes[i].Name < nodes[j].Name
+		})
+		for i := range nodes {
+			nodes[i].Children = attach(children[nodes[i].ID])
+		}
+		return nodes
+	}
+
+	roots = attach(roots)
+	if roots == nil {
+		roots = []models.Category{}
+	}
+	return roots
+}
+
+// buildCategoryIndex 深度优先展开分类树，返回各节点的指针
+// 参数: roots - 分类树
+// 返回值: 所有节点
+func buildCategoryIndex(roots []models.Category) []*models.Category {
+	var nodes []*models.Category
+	for i := range roots {
+		nodes = append(nodes, &roots[i])
+		nodes = append(nodes, buildCategoryIndex(roots[i].Children)...)
+	}
+	return nodes
+}
+
+// categoryExists 判断分类是否存在
+// 参数: categories - 全部分类, id - 分类ID
+func categoryExists(categories []models.Category, id uint) bool {
+	for _, category := range categories {
+		if category.ID == id {
+			return true
+		}
+	}
+	return false
+}
+
+// descendantCategoryIDs 获取分类自身及全部子孙分类的ID
+// 参数: categories - 全部分类, rootID - 起始分类ID
+// 返回值: 分类ID列表（包含 rootID）
+func descendantCategoryIDs(categories []models.Category, rootID uint) []uint {
+	children := make(map[uint][]uint)
+	for _, category := range categories {
+		if category.ParentID != nil {
+			children[*category.ParentID] = append(children[*category.ParentID], category.ID)
+		}
+	}
+
+	ids := []uint{rootID}
+	for i := 0; i < len(ids); i++ {
+		ids = append(ids, children[ids[i]]...)
+	}
+	return ids
+}
+
+// categoryPath 获取从顶级分类到指定分类的路径
+// 参数: categories - 全部分类, id - 分类ID
+// 返回值: 路径上的分类（不含子分类）
+func categoryPath(categories []models.Category, id uint) []gin.H {
+	byID := make(map[uint]models.Category, len(categories))
+	for _, category := range categories {
+		byID[category.ID] = category
+	}
+
+	path := []gin.H{}
+	for current, ok := byID[id]; ok; {
+		path = append([]gin.H{{"id": current.ID, "name": current.Name}}, path...)
+		if current.ParentID == nil || len(path) > len(categories) {
+			break
+		}
+		current, ok = byID[*current.ParentID]
+	}
+	return path
+}
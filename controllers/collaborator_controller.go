@@ -0,0 +1,293 @@
+package controllers
+
+import (
+	"blog-system/database"
+	"blog-system/models"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"net/http"
+	"strconv"
+)
+
+// 协作者操作错误
+var (
+	errCollaboratorNotFound = errors.New("协作者不存在")
+	errCollaboratorExists   = errors.New("该用户已是协作者")
+)
+
+// GetCollaborators 获取文章的作者及协作者列表
+// 参数: c - Gin上下文
+func GetCollaborators(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的文章ID"})
+		return
+	}
+
+	var post models.Post
+	if err := database.DB.Preload("Collaborators.User").First(&post, id).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "文章不存在"})
+		return
+	}
+
+	var author models.UserSummary
+	if err := database.DB.First(&author, post.UserID).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "获取协作者失败"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"author":        author,
+		"collaborators": post.Collaborators,
+	})
+}
+
+// AddCollaborator 添加协作者（仅所有者）
+// 请求体: user_id 或 username 指定用户, role - owner|coauthor|reviewer
+// 参数: c - Gin上下文
+func AddCollaborator(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的文章ID"})
+		return
+	}
+
+	var data map[string]interface{}
+	if err := c.ShouldBindJSON(&data); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	role, err := parseCollaboratorRole(data["role"])
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	user, err := findCollaboratorUser(data)
+	if err != nil {
+		respondCollaboratorError(c, err)
+		return
+	}
+
+	collaborator := models.PostCollaborator{PostID: uint(id), UserID: user.ID, Role: role}
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		var post models.Post
+		if err := tx.Select("id", "user_id").First(&post, id).Error; err != nil {
+			return err
+		}
+		if post.UserID == user.ID {
+			return invalidInput("该用户是文章作者")
+		}
+		var count int64
+		if err := tx.Model(&models.PostCollaborator{}).Where("post_id = ? AND user_id = ?", id, user.ID).Count(&count).Error; err != nil {
+			return err
+		}
+		if count > 0 {
+			return errCollaboratorExists
+		}
+		return tx.Create(&collaborator).Error
+	})
+	if err != nil {
+		respondCollaboratorError(c, err)
+		return
+	}
+
+	collaborator.User = user
+	c.JSON(http.StatusCreated, collaborator)
+}
+
+// UpdateCollaborator 修改协作者角色（仅所有者）
+// 请求体: role - owner|coauthor|reviewer
+// 参数: c - Gin上下文
+func UpdateCollaborator(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的文章ID"})
+		return
+	}
+	userID, err := strconv.ParseUint(c.Param("user_id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的用户ID"})
+		return
+	}
+
+	var data map[string]interface{}
+	if err := c.ShouldBindJSON(&data); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	role, err := parseCollaboratorRole(data["role"])
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	result := database.DB.Model(&models.PostCollaborator{}).
+		Where("post_id = ? AND user_id = ?", id, userID).Update("role", role)
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "更新协作者失败"})
+		return
+	}
+	if result.RowsAffected == 0 {
+		respondCollaboratorError(c, errCollaboratorNotFound)
+		return
+	}
+
+	var collaborator models.PostCollaborator
+	if err := database.DB.Preload("User").Where("post_id = ? AND user_id = ?", id, userID).First(&collaborator).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "获取协作者失败"})
+		return
+	}
+	c.JSON(http.StatusOK, collaborator)
+}
+
+// RemoveCollaborator 移除协作者
+// 所有者可以移除任意协作者，其他协作者只能退出协作
+// 参数: c - Gin上下文
+func RemoveCollaborator(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的文章ID"})
+		return
+	}
+	userID, err := strconv.ParseUint(c.Param("user_id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的用户ID"})
+		return
+	}
+
+	currentID, _ := currentUserID(c)
+	if c.GetString("post_role") != models.CollaboratorOwner && uint(userID) != currentID {
+		c.JSON(http.StatusForbidden, gin.H{"error": "You are not the owner of this post"})
+		return
+	}
+
+	result := database.DB.Where("post_id = ? AND user_id = ?", id, userID).Delete(&models.PostCollaborator{})
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "移除协作者失败"})
+		return
+	}
+	if result.RowsAffected == 0 {
+		// 文章作者没有协作者记录，需先转让文章
+		respondCollaboratorError(c, errCollaboratorNotFound)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "协作者已移除"})
+}
+
+// TransferPost 将文章转让给其他用户（仅所有者）
+// 新作者原有的协作者记录被移除，原作者保留为合著者
+// 请求体: user_id 或 username 指定新作者
+// 参数: c - Gin上下文
+func TransferPost(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的文章ID"})
+		return
+	}
+
+	var data map[string]interface{}
+	if err := c.ShouldBindJSON(&data); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	user, err := findCollaboratorUser(data)
+	if err != nil {
+		respondCollaboratorError(c, err)
+		return
+	}
+
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		var post models.Post
+		if err := tx.Select("id", "user_id").First(&post, id).Error; err != nil {
+			return err
+		}
+		if post.UserID == user.ID {
+			return invalidInput("该用户已是文章作者")
+		}
+
+		if err := tx.Where("post_id = ? AND user_id = ?", id, user.ID).Delete(&models.PostCollaborator{}).Error; err != nil {
+			return err
+		}
+		previous := models.PostCollaborator{PostID: post.ID, UserID: post.UserID, Role: models.CollaboratorCoAuthor}
+		if err := tx.Create(&previous).Error; err != nil {
+			return err
+		}
+		// 转让不改变内容，不刷新更新时间和版本号
+		return tx.Model(&post).UpdateColumn("user_id", user.ID).Error
+	})
+	if err != nil {
+		respondCollaboratorError(c, err)
+		return
+	}
+
+	var post models.Post
+	if err := database.DB.Preload("Tags").Preload("Category").Preload("Collaborators.User").First(&post, id).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "获取文章失败"})
+		return
+	}
+	c.JSON(http.StatusOK, post)
+}
+
+// parseCollaboratorRole 解析协作角色
+// 参数: raw - JSON 中的 role 字段
+// 返回值: 角色, 错误信息
+func parseCollaboratorRole(raw interface{}) (string, error) {
+	role, ok := raw.(string)
+	if !ok || !models.ValidCollaboratorRole(role) {
+		return "", invalidInput("role must be owner, coauthor or reviewer")
+	}
+	return role, nil
+}
+
+// findCollaboratorUser 根据 user_id 或 username 查找用户
+// 参数: data - 请求体
+// 返回值: 用户摘要, 错误信息
+func findCollaboratorUser(data map[string]interface{}) (*models.UserSummary, error) {
+	var user models.UserSummary
+	query := database.DB
+	if raw, ok := data["user_id"]; ok {
+		userID, err := parseOptionalID(raw)
+		if err != nil || userID == nil {
+			return nil, invalidInput("user_id must be a positive integer")
+		}
+		query = query.Where("id = ?", *userID)
+	} else if username, ok := data["username"].(string); ok && username != "" {
+		query = query.Where("username = ?", username)
+	} else {
+		return nil, invalidInput("user_id or username is required")
+	}
+
+	if err := query.First(&user).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, invalidInput("用户不存在")
+		}
+		return nil, err
+	}
+	return &user, nil
+}
+
+// respondCollaboratorError 将协作者操作的错误映射为HTTP响应
+func respondCollaboratorError(c *gin.Context, err error) {
+	switch {
+	case isInputError(err):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, errCollaboratorNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, errCollaboratorExists):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "文章不存在"})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+	}
+}
+
+// postRoleOf 获取当前用户（可未登录）在文章中的协作角色
+// 参数: c - Gin上下文, post - 文章
+// 返回值: 角色，无权限时为空字符串, 错误信息
+func postRoleOf(c *gin.Context, post *models.Post) (string, error) {
+	userId, _ := currentUserID(c)
+	return post.RoleOf(database.DB, userId)
+}
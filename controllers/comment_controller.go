@@ -1,15 +1,24 @@
 package controllers
 
 import (
+	"blog-system/config"
 	"blog-system/database"
 	"blog-system/models"
+	"blog-system/spam"
+	"blog-system/utils"
+	"fmt"
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
 	"log"
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
 )
 
 // CreateComment 创建评论
+// 需要满足文章的评论策略；未登录时以游客身份评论，需要文章允许游客评论，并提交 guest_name、guest_email 以及
+// 工作量证明题目的 challenge 和 nonce；游客评论始终进入人工审核
 // 参数: c - Gin上下文
 func CreateComment(c *gin.Context) {
 	var comment models.Comment
@@ -46,32 +55,241 @@ func CreateComment(c *gin.Context) {
 		return
 	}
 
-	// 从上下文中获取用户ID
+	// 垃圾评论检查的附加字段：website 为蜜罐字段，rendered_at 为表单渲染时间（Unix 秒）
+	submission := spam.Submission{Content: comment.Content, PostID: comment.PostID, ReceivedAt: time.Now()}
+	submission.Honeypot, _ = data["website"].(string)
+	if raw, ok := data["rendered_at"]; ok && raw != nil {
+		renderedAt, ok := raw.(float64)
+		if !ok || renderedAt <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "rendered_at must be a unix timestamp"})
+			return
+		}
+		submission.RenderedAt = time.Unix(0, int64(renderedAt*float64(time.Second)))
+	}
+
+	// 回复时指定父评论
+	parentID, err := parseOptionalID(data["parent_id"])
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "parent_id " + err.Error()})
+		return
+	}
+	comment.ParentID = parentID
+
+	// 从上下文中获取用户ID，未登录时为游客评论
 	if userIdValue, exists := c.Get("userid"); exists {
 		// 类型安全转换
 		if userId, ok := userIdValue.(uint); ok {
-			comment.UserID = userId
+			comment.UserID = &userId
 		} else {
 			// 记录详细的类型错误日志
 			log.Printf("invalid userid type: expected uint, got %T", userIdValue)
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "invalid user data"})
 			return
 		}
+	}
+
+	// 只能评论有权查看全文的文章
+	var post models.Post
+	if err := database.DB.Select("id", "user_id", "status", "published_at", "visibility", "password_hash", "hidden_at", "comment_moderation", "comment_policy", "guest_comments").First(&post, comment.PostID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "文章不存在"})
+		return
+	}
+	if !checkPostAccess(c, &post) {
+		return
+	}
+
+	// 按文章的评论策略检查当前用户能否评论
+	var userID uint
+	if comment.UserID != nil {
+		userID = *comment.UserID
+	}
+	policy, err := commentAccess(&post, userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+	if !policy.Allowed {
+		c.JSON(commentDeniedStatus(policy.Reason), gin.H{"error": commentDeniedMessages[policy.Reason], "reason": policy.Reason})
+		return
+	}
+	if comment.UserID == nil {
+		if comment.GuestName, comment.GuestEmail, err = parseGuest(data); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	// 回复必须与父评论属于同一篇文章，且不超过最大嵌套层数
+	if comment.ParentID != nil {
+		var parent models.Comment
+		// 未通过审核或被隐藏的评论只有其作者可以回复，游客只能回复可见的评论
+		if err := database.DB.Select("id", "post_id", "user_id", "depth", "status", "hidden_at").First(&parent, *comment.ParentID).Error; err != nil ||
+			((parent.Status != models.CommentStatusApproved || parent.HiddenAt != nil) && (comment.UserID == nil || parent.UserID == nil || *parent.UserID != *comment.UserID)) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "父评论不存在"})
+			return
+		}
+		if parent.PostID != comment.PostID {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "父评论不属于该文章"})
+			return
+		}
+		maxDepth := config.LoadConfig().CommentMaxDepth
+		if parent.Depth+1 > maxDepth {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("replies cannot be nested more than %d levels", maxDepth)})
+			return
+		}
+		comment.Depth = parent.Depth + 1
+	}
+
+	// 游客需要提交正确的工作量证明答案，每个题目只能使用一次
+	if comment.UserID == nil {
+		if err := claimChallenge(data, comment.PostID); err != nil {
+			if isInputError(err) {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			} else {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+			}
+			return
+		}
 	} else {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		submission.UserID = *comment.UserID
+	}
+
+	// 按审核模式和垃圾评论检查结果决定评论是否需要审核
+	if err := screenComment(&post, &comment, &submission); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
 		return
 	}
 
-	// 创建评论 - 添加错误处理
-	if err := database.DB.Create(&comment).Error; err != nil {
+	// 创建评论并记录其中的 @提及 - 添加错误处理
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&comment).Error; err != nil {
+			return err
+		}
+		var err error
+		comment.Mentions, err = saveMentions(tx, models.MentionSourceComment, comment.ID, comment.PostID, comment.UserID, comment.Content)
+		return err
+	})
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "创建评论失败: " + err.Error()})
 		return
 	}
+	if comment.SpamScore > 0 {
+		log.Printf("评论 %d 疑似垃圾评论（得分 %.2f，状态 %s）: %s", comment.ID, comment.SpamScore, comment.Status, comment.SpamReasons)
+	}
 
 	c.JSON(http.StatusCreated, comment)
 }
 
-// GetCommentsByPost 获取文章的所有评论
+// UpdateComment 编辑评论内容（评论作者），只能在发布后的编辑时限内修改
+// 请求体: content - 新的评论内容
+// 参数: c - Gin上下文
+func UpdateComment(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的评论ID"})
+		return
+	}
+
+	var data map[string]interface{}
+	if err := c.ShouldBindJSON(&data); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	content, ok := data["content"].(string)
+	if !ok || strings.TrimSpace(content) == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "content must be a non-empty string"})
+		return
+	}
+
+	var comment models.Comment
+	if err := database.DB.First(&comment, id).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "评论不存在"})
+		return
+	}
+	window := config.LoadConfig().CommentEditWindow
+	if window > 0 && time.Since(comment.CreatedAt) > window {
+		c.JSON(http.StatusForbidden, gin.H{"error": "已超过评论的编辑时限"})
+		return
+	}
+
+	// 修改后的内容重新经过审核模式和垃圾评论检查，已通过的评论可能转入审核
+	var post models.Post
+	if err := database.DB.Select("id", "user_id", "comment_moderation").First(&post, comment.PostID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "文章不存在"})
+		return
+	}
+	now := time.Now()
+	previous := comment
+	submission := spam.Submission{Content: content, UserID: *comment.UserID, PostID: comment.PostID, ReceivedAt: now}
+	if err := screenEdit(&post, &comment, &submission); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		// 训练结果基于修改前的内容，内容改变后撤销，由重新审核的结果训练
+		if err := spam.Forget(tx, previous.Content, previous.TrainedAs); err != nil {
+			return err
+		}
+		if err := tx.Model(&comment).Updates(map[string]interface{}{
+			"content": content, "edited_at": now, "status": comment.Status,
+			"spam_score": comment.SpamScore, "spam_reasons": comment.SpamReasons, "trained_as": "",
+		}).Error; err != nil {
+			return err
+		}
+		var err error
+		comment.Mentions, err = saveMentions(tx, models.MentionSourceComment, comment.ID, comment.PostID, comment.UserID, content)
+		return err
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "编辑评论失败"})
+		return
+	}
+	if comment.Status != previous.Status {
+		log.Printf("评论 %d 编辑后状态由 %s 变为 %s（垃圾评论得分 %.2f）: %s", comment.ID, previous.Status, comment.Status, comment.SpamScore, comment.SpamReasons)
+	}
+
+	c.JSON(http.StatusOK, comment)
+}
+
+// DeleteComment 删除评论（评论作者或文章所有者）
+// 回复不会随之删除，在树形结构中作为顶级评论返回
+// 参数: c - Gin上下文
+func DeleteComment(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的评论ID"})
+		return
+	}
+
+	result := database.DB.Delete(&models.Comment{}, id)
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "删除评论失败"})
+		return
+	}
+	if result.RowsAffected == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "评论不存在"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "评论已删除"})
+}
+
+// commentSorts 评论列表支持的排序方式
+var commentSorts = map[string]string{
+	"oldest": "comments.created_at, comments.id",
+	"newest": "comments.created_at DESC, comments.id DESC",
+	// 按已通过审核的直接回复数排序
+	"top": "(SELECT COUNT(*) FROM comments AS replies WHERE replies.parent_id = comments.id" +
+		" AND replies.status = '" + models.CommentStatusApproved + "' AND replies.hidden_at IS NULL AND replies.deleted_at IS NULL) DESC," +
+		" comments.created_at DESC, comments.id DESC",
+	// 按投票的 Wilson 置信下界排序，得分相同时赞成票多的在前
+	"score": "comments.vote_score DESC, comments.upvotes DESC, comments.created_at DESC, comments.id DESC",
+}
+
+// GetCommentsByPost 分页获取文章的评论
+// 查询参数: format - flat（默认，平铺列表并带 parent_id）或 tree（按顶级评论分页，回复嵌套在 replies 中）,
+// sort - oldest（默认）、newest、top（回复最多）或 score（投票得分最高）, page/page_size - 分页
 // 参数: c - Gin上下文
 func GetCommentsByPost(c *gin.Context) {
 	// 从URL参数获取文章ID
@@ -81,13 +299,176 @@ func GetCommentsByPost(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的文章ID"})
 		return
 	}
+	format := c.DefaultQuery("format", "flat")
+	if format != "flat" && format != "tree" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "format must be flat or tree"})
+		return
+	}
+	sort := c.DefaultQuery("sort", "oldest")
+	order, ok := commentSorts[sort]
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "sort must be oldest, newest, top or score"})
+		return
+	}
+	page, pageSize := parsePagination(c)
 
 	var post models.Post
-	// 查询该文章的所有评论 - 添加错误处理
-	if err := database.DB.Preload("Comments").Where("id = ?", id).First(&post).Error; err != nil {
+	if err := database.DB.Select("id", "user_id", "status", "visibility", "password_hash", "hidden_at").First(&post, id).Error; err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "文章不存在"})
 		return
 	}
 
-	c.JSON(http.StatusOK, post.Comments)
+	// 评论与文章正文的可见范围相同
+	if !checkPostAccess(c, &post) {
+		return
+	}
+	c.Header("Vary", "Authorization, "+postAccessHeader)
+	if isRestrictedPost(&post) {
+		c.Header("Cache-Control", "private, no-cache")
+	}
+
+	var total int64
+	if err := visibleComments(c, post.ID).Count(&total).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "获取评论失败"})
+		return
+	}
+
+	// 树形结构按顶级评论分页，父评论不可见的回复也视为顶级评论
+	query := visibleComments(c, post.ID)
+	pageTotal := total
+	if format == "tree" {
+		query = query.Where("comments.parent_id IS NULL OR comments.parent_id NOT IN (?)",
+			visibleComments(c, post.ID).Select("comments.id"))
+		if err := query.Session(&gorm.Session{}).Count(&pageTotal).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "获取评论失败"})
+			return
+		}
+	}
+	var comments []models.Comment
+	if err := query.Order(order).Offset((page - 1) * pageSize).Limit(pageSize).Find(&comments).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "获取评论失败"})
+		return
+	}
+
+	// 逐层加载回复，每层一次查询
+	replies := make(map[uint][]models.Comment)
+	all := make([]*models.Comment, 0, len(comments))
+	for i := range comments {
+		all = append(all, &comments[i])
+	}
+	if format == "tree" {
+		for parents := all; len(parents) > 0; {
+			ids := make([]uint, len(parents))
+			for i, parent := range parents {
+				ids[i] = parent.ID
+			}
+			var children []models.Comment
+			if err := visibleComments(c, post.ID).Where("comments.parent_id IN ?", ids).
+				Order("comments.created_at, comments.id").Find(&children).Error; err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "获取评论失败"})
+				return
+			}
+			parents = make([]*models.Comment, len(children))
+			for i := range children {
+				parents[i] = &children[i]
+			}
+			all = append(all, parents...)
+		}
+	}
+	if err := fillCommentAuthors(all); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "获取评论失败"})
+		return
+	}
+	if err := fillCommentMentions(all); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "获取评论失败"})
+		return
+	}
+	userId, _ := currentUserID(c)
+	if err := fillMyVotes(all, userId); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "获取评论失败"})
+		return
+	}
+	for _, comment := range all[len(comments):] {
+		replies[*comment.ParentID] = append(replies[*comment.ParentID], *comment)
+	}
+
+	lastModified, err := lastChange(&models.Comment{}, "post_id = ?", post.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "获取评论失败"})
+		return
+	}
+	// 投票只更新计数而不更新评论的修改时间，需要单独计入
+	votedAt, err := lastChange(&models.CommentVote{}, "comment_id IN (?)",
+		database.DB.Unscoped().Model(&models.Comment{}).Select("id").Where("post_id = ?", post.ID))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "获取评论失败"})
+		return
+	}
+	lastModified = latestTime(lastModified, votedAt)
+	body := gin.H{
+		"total":     total,
+		"page":      page,
+		"page_size": pageSize,
+		"sort":      sort,
+		"items":     attachReplies(comments, replies),
+	}
+	if format == "tree" {
+		body["threads"] = pageTotal
+	}
+	respondCached(c, "", lastModified, body)
+}
+
+// visibleComments 构造当前用户可以看到的文章评论查询
+// 未通过审核或因举报被隐藏的评论只对其作者可见
+// 参数: c - Gin上下文, postID - 文章ID
+func visibleComments(c *gin.Context, postID uint) *gorm.DB {
+	query := database.DB.Model(&models.Comment{}).Where("comments.post_id = ?", postID)
+	if userId, ok := currentUserID(c); ok {
+		return query.Where("(comments.status = ? AND comments.hidden_at IS NULL) OR comments.user_id = ?", models.CommentStatusApproved, userId)
+	}
+	return query.Where("comments.status = ? AND comments.hidden_at IS NULL", models.CommentStatusApproved)
+}
+
+// attachReplies 将回复按父评论嵌套到评论的 replies 中
+// 参数: nodes - 同级评论, replies - 按父评论ID分组的回复
+// 返回值: 嵌套后的评论列表
+func attachReplies(nodes []models.Comment, replies map[uint][]models.Comment) []models.Comment {
+	for i := range nodes {
+		nodes[i].Replies = attachReplies(replies[nodes[i].ID], replies)
+	}
+	if nodes == nil {
+		nodes = []models.Comment{}
+	}
+	return nodes
+}
+
+// fillCommentAuthors 一次查询为评论填充作者摘要，游客评论使用游客昵称
+// 参数: comments - 评论列表
+func fillCommentAuthors(comments []*models.Comment) error {
+	if len(comments) == 0 {
+		return nil
+	}
+	ids := make([]uint, 0, len(comments))
+	for _, comment := range comments {
+		if comment.UserID != nil {
+			ids = append(ids, *comment.UserID)
+		}
+	}
+	var users []models.User
+	if err := database.DB.Select("id", "username", "email").Where("id IN ?", ids).Find(&users).Error; err != nil {
+		return err
+	}
+	authors := make(map[uint]*models.UserSummary, len(users))
+	for _, user := range users {
+		authors[user.ID] = &models.UserSummary{ID: user.ID, Username: user.Username, Avatar: utils.AvatarURL(user.Email)}
+	}
+	for _, comment := range comments {
+		if comment.UserID == nil {
+			// 游客只展示昵称，不根据邮箱生成头像以免泄露邮箱
+			comment.Author = &models.UserSummary{Username: comment.GuestName, Guest: true}
+		} else {
+			comment.Author = authors[*comment.UserID]
+		}
+	}
+	return nil
 }
@@ -0,0 +1,81 @@
+package controllers
+
+import (
+	"blog-system/database"
+	"blog-system/models"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm/clause"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// FollowUser 关注用户，重复关注不会报错
+// 参数: c - Gin上下文
+func FollowUser(c *gin.Context) {
+	followerID, followeeID, ok := loadFollowTarget(c)
+	if !ok {
+		return
+	}
+
+	follow := models.Follow{FollowerID: followerID, FolloweeID: followeeID, CreatedAt: time.Now()}
+	if err := database.DB.Clauses(clause.OnConflict{DoNothing: true}).Create(&follow).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "关注失败"})
+		return
+	}
+
+	respondFollow(c, followeeID, true)
+}
+
+// UnfollowUser 取消关注用户
+// 参数: c - Gin上下文
+func UnfollowUser(c *gin.Context) {
+	followerID, followeeID, ok := loadFollowTarget(c)
+	if !ok {
+		return
+	}
+
+	err := database.DB.Where("follower_id = ? AND followee_id = ?", followerID, followeeID).
+		Delete(&models.Follow{}).Error
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "取消关注失败"})
+		return
+	}
+
+	respondFollow(c, followeeID, false)
+}
+
+// loadFollowTarget 解析当前用户和要关注的用户，失败时直接写入错误响应
+// 参数: c - Gin上下文
+// 返回值: 当前用户ID, 被关注用户ID, 是否成功
+func loadFollowTarget(c *gin.Context) (uint, uint, bool) {
+	userId, ok := currentUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return 0, 0, false
+	}
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil || id <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的用户ID"})
+		return 0, 0, false
+	}
+	if uint(id) == userId {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "不能关注自己"})
+		return 0, 0, false
+	}
+	if err := database.DB.Select("id").First(&models.User{}, id).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "用户不存在"})
+		return 0, 0, false
+	}
+	return userId, uint(id), true
+}
+
+// respondFollow 返回关注状态和被关注用户的关注者数量
+func respondFollow(c *gin.Context, followeeID uint, following bool) {
+	var followers int64
+	if err := database.DB.Model(&models.Follow{}).Where("followee_id = ?", followeeID).Count(&followers).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"user_id": followeeID, "following": following, "followers": followers})
+}
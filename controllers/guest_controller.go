@@ -0,0 +1,116 @@
+package controllers
+
+import (
+	"blog-system/config"
+	"blog-system/database"
+	"blog-system/models"
+	"blog-system/utils"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm/clause"
+	"net/http"
+	"net/mail"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// 游客信息长度限制
+const (
+	maxGuestNameLength  = 64
+	maxGuestEmailLength = 255
+)
+
+// GetCommentChallenge 获取游客评论的工作量证明题目
+// 客户端需要找到 nonce，使 sha256(challenge + ":" + nonce) 的前 difficulty 个比特为 0，
+// 然后在创建评论时一并提交 challenge 和 nonce；每个题目只能使用一次
+// 查询参数: post_id - 文章ID
+// 参数: c - Gin上下文
+func GetCommentChallenge(c *gin.Context) {
+	postID, err := strconv.ParseUint(c.Query("post_id"), 10, 64)
+	if err != nil || postID == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "post_id is required"})
+		return
+	}
+
+	var post models.Post
+	if err := database.DB.Select("id", "user_id", "status", "published_at", "visibility", "password_hash", "hidden_at", "comment_policy", "guest_comments").First(&post, postID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "文章不存在"})
+		return
+	}
+	if !checkPostAccess(c, &post) {
+		return
+	}
+	policy, err := commentAccess(&post, 0)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+	if !policy.Allowed {
+		c.JSON(http.StatusForbidden, gin.H{"error": "该文章不允许游客评论", "reason": policy.Reason})
+		return
+	}
+
+	cfg := config.LoadConfig()
+	challenge, expiresAt, err := utils.GenerateChallenge(post.ID, cfg.GuestPowBits, cfg.JWTSecret, cfg.GuestChallengeTTL)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "生成验证题目失败"})
+		return
+	}
+
+	c.Header("Cache-Control", "no-store")
+	c.JSON(http.StatusOK, gin.H{
+		"challenge":  challenge,
+		"algorithm":  "sha256",
+		"difficulty": cfg.GuestPowBits,
+		"expires_at": expiresAt,
+	})
+}
+
+// parseGuest 解析游客评论的昵称和邮箱
+// 参数: data - 请求体
+// 返回值: 昵称, 邮箱, 错误信息
+func parseGuest(data map[string]interface{}) (string, string, error) {
+	name, _ := data["guest_name"].(string)
+	name = strings.TrimSpace(name)
+	if name == "" || utf8.RuneCountInString(name) > maxGuestNameLength {
+		return "", "", invalidInput("guest_name must be 1-%d characters", maxGuestNameLength)
+	}
+
+	email, _ := data["guest_email"].(string)
+	email = strings.TrimSpace(email)
+	address, err := mail.ParseAddress(email)
+	if err != nil || address.Address != email || len(email) > maxGuestEmailLength {
+		return "", "", invalidInput("guest_email must be a valid email address")
+	}
+	return name, email, nil
+}
+
+// claimChallenge 验证游客提交的题目答案并将题目标记为已使用
+// 参数: data - 请求体, postID - 评论的文章ID
+// 返回值: 错误信息
+func claimChallenge(data map[string]interface{}, postID uint) error {
+	challenge, _ := data["challenge"].(string)
+	nonce, _ := data["nonce"].(string)
+	if challenge == "" || nonce == "" {
+		return invalidInput("challenge and nonce are required")
+	}
+	claims, err := utils.VerifyChallenge(challenge, nonce, postID, config.LoadConfig().JWTSecret)
+	if err != nil {
+		return invalidInput("%s", err.Error())
+	}
+
+	// 已使用的题目记录在数据库中，由主键保证多个实例之间也只能使用一次；过期的记录顺带清理
+	if err := database.DB.Where("expires_at < ?", time.Now()).Delete(&models.UsedChallenge{}).Error; err != nil {
+		return err
+	}
+	result := database.DB.Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&models.UsedChallenge{ID: claims.ID, ExpiresAt: claims.ExpiresAt.Time})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return invalidInput("验证题目已被使用")
+	}
+	return nil
+}
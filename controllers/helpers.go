@@ -0,0 +1,69 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"log"
+	"strconv"
+)
+
+// 分页参数默认值
+const (
+	defaultPageSize = 10
+	maxPageSize     = 50
+)
+
+// inputError 请求参数错误，应返回400
+type inputError struct {
+	msg string
+}
+
+func (e *inputError) Error() string {
+	return e.msg
+}
+
+// invalidInput 构造请求参数错误
+func invalidInput(format string, args ...interface{}) error {
+	return &inputError{msg: fmt.Sprintf(format, args...)}
+}
+
+// isInputError 判断是否为请求参数错误
+func isInputError(err error) bool {
+	var target *inputError
+	return errors.As(err, &target)
+}
+
+// parsePagination 解析分页参数 page 和 page_size
+// 参数: c - Gin上下文
+// 返回值: 页码（从1开始）, 每页数量
+func parsePagination(c *gin.Context) (int, int) {
+	page, err := strconv.Atoi(c.DefaultQuery("page", "1"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+	pageSize, err := strconv.Atoi(c.DefaultQuery("page_size", strconv.Itoa(defaultPageSize)))
+	if err != nil || pageSize < 1 {
+		pageSize = defaultPageSize
+	}
+	if pageSize > maxPageSize {
+		pageSize = maxPageSize
+	}
+	return page, pageSize
+}
+
+// currentUserID 获取认证中间件写入上下文的当前用户ID
+// 参数: c - Gin上下文
+// 返回值: 用户ID, 是否已认证
+func currentUserID(c *gin.Context) (uint, bool) {
+	userIdValue, exists := c.Get("userid")
+	if !exists {
+		return 0, false
+	}
+	userId, ok := userIdValue.(uint)
+	if !ok {
+		log.Printf("invalid userid type: expected uint, got %T", userIdValue)
+		return 0, false
+	}
+	return userId, userId != 0
+}
@@ -0,0 +1,292 @@
+package controllers
+
+import (
+	"blog-system/database"
+	"blog-system/models"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm/clause"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// placedPost 推荐位管理列表中的文章摘要
+type placedPost struct {
+	ID         uint   `json:"id"`
+	Title      string `json:"title"`
+	Status     string `json:"status"`
+	Visibility string `json:"visibility"`
+}
+
+// placementItem 推荐位管理列表项
+// 已过期或文章不再公开列出的推荐不会出现在首页，但仍保留在管理列表中
+type placementItem struct {
+	models.HomePlacement
+	Expired bool        `json:"expired"`
+	Post    *placedPost `json:"post"`
+}
+
+// GetHome 获取首页内容：置顶文章、精选文章和最新文章
+// 同一篇文章只出现在一个区块中，优先级为置顶、精选、最新
+// 查询参数: latest - 最新文章数量，默认 10，最多 50
+// 参数: c - Gin上下文
+func GetHome(c *gin.Context) {
+	latestCount, err := strconv.Atoi(c.DefaultQuery("latest", strconv.Itoa(defaultPageSize)))
+	if err != nil || latestCount < 1 || latestCount > maxPageSize {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "latest must be between 1 and " + strconv.Itoa(maxPageSize)})
+		return
+	}
+
+	now := time.Now()
+	shown := make(map[uint]bool)
+	pinned, err := loadPlacedPosts(models.SectionPinned, now, shown)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "获取首页失败"})
+		return
+	}
+	featured, err := loadPlacedPosts(models.SectionFeatured, now, shown)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "获取首页失败"})
+		return
+	}
+
+	query := database.DB.Preload("Tags").Preload("Category").Scopes(listedPosts)
+	if len(shown) > 0 {
+		ids := make([]uint, 0, len(shown))
+		for id := range shown {
+			ids = append(ids, id)
+		}
+		query = query.Where("posts.id NOT IN ?", ids)
+	}
+	var latest []models.Post
+	if err := query.Order("posts.published_at DESC, posts.id DESC").Limit(latestCount).Find(&latest).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "获取首页失败"})
+		return
+	}
+
+	all := append(append(postPointers(pinned), postPointers(featured)...), postPointers(latest)...)
+	for _, post := range all {
+		ensureRendered(post)
+		if post.Visibility == models.VisibilityPassword {
+			lockPost(post)
+		}
+	}
+	userId, _ := currentUserID(c)
+	interactedAt, err := fillInteractions(all, userId)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "获取首页失败"})
+		return
+	}
+	c.Header("Vary", "Authorization")
+
+	// 推荐过期也会改变首页内容，最近一次过期的时间同样计入 Last-Modified
+	postsChangedAt, err := lastChange(&models.Post{}, "")
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "获取首页失败"})
+		return
+	}
+	placementsChangedAt, err := lastChange(&models.HomePlacement{}, "")
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "获取首页失败"})
+		return
+	}
+	taxonomyChangedAt, err := lastTaxonomyChange()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "获取首页失败"})
+		return
+	}
+	var expired []time.Time
+	if err := database.DB.Model(&models.HomePlacement{}).Where("expires_at <= ?", now).
+		Order("expires_at DESC").Limit(1).Pluck("expires_at", &expired).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "获取首页失败"})
+		return
+	}
+	lastModified := latestTime(postsChangedAt, placementsChangedAt, interactedAt, taxonomyChangedAt)
+	if len(expired) > 0 {
+		lastModified = latestTime(lastModified, expired[0])
+	}
+
+	respondCached(c, "", lastModified, gin.H{
+		"pinned":   pinned,
+		"featured": featured,
+		"latest":   latest,
+	})
+}
+
+// GetHomePlacements 获取全部首页推荐（管理员），包括已过期的推荐
+// 参数: c - Gin上下文
+func GetHomePlacements(c *gin.Context) {
+	var placements []models.HomePlacement
+	if err := database.DB.Order("section, position, id").Find(&placements).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "获取首页推荐失败"})
+		return
+	}
+
+	postIDs := make([]uint, len(placements))
+	for i, placement := range placements {
+		postIDs[i] = placement.PostID
+	}
+	var posts []models.Post
+	if err := database.DB.Select("id", "title", "status", "visibility").Where("id IN ?", postIDs).Find(&posts).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "获取首页推荐失败"})
+		return
+	}
+	postByID := make(map[uint]*placedPost, len(posts))
+	for _, post := range posts {
+		postByID[post.ID] = &placedPost{ID: post.ID, Title: post.Title, Status: post.Status, Visibility: post.Visibility}
+	}
+
+	now := time.Now()
+	items := make([]placementItem, len(placements))
+	for i, placement := range placements {
+		items[i] = placementItem{
+			HomePlacement: placement,
+			Expired:       placement.ExpiresAt != nil && !placement.ExpiresAt.After(now),
+			Post:          postByID[placement.PostID],
+		}
+	}
+
+	c.JSON(http.StatusOK, items)
+}
+
+// SetHomePlacement 将文章加入首页推荐位或修改其排序和过期时间（管理员）
+// 请求体: position - 可选，排序（数值小的在前，默认 0）, expires_at - 可选，RFC3339 格式的过期时间
+// 参数: c - Gin上下文
+func SetHomePlacement(c *gin.Context) {
+	section, postID, ok := parsePlacementTarget(c)
+	if !ok {
+		return
+	}
+	userId, _ := currentUserID(c)
+
+	placement := models.HomePlacement{PostID: postID, Section: section, UserID: userId}
+	if c.Request.ContentLength != 0 {
+		var data map[string]interface{}
+		if err := c.ShouldBindJSON(&data); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if position, ok := data["position"].(float64); ok {
+			placement.Position = int(position)
+		} else if data["position"] != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "position must be a number"})
+			return
+		}
+		if raw := data["expires_at"]; raw != nil {
+			value, _ := raw.(string)
+			expiresAt, err := time.Parse(time.RFC3339, value)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "expires_at must be an RFC3339 time"})
+				return
+			}
+			if !expiresAt.After(time.Now()) {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "expires_at must be in the future"})
+				return
+			}
+			placement.ExpiresAt = &expiresAt
+		}
+	}
+
+	// 未发布或未公开列出的文章也可以预先加入推荐位，首页只展示当前公开列出的文章
+	if err := database.DB.Select("id").First(&models.Post{}, postID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "文章不存在"})
+		return
+	}
+
+	// 已移除的推荐被恢复，PUT 语义下未提供的字段重置为默认值
+	now := time.Now()
+	placement.CreatedAt, placement.UpdatedAt = now, now
+	err := database.DB.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "post_id"}, {Name: "section"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{
+			"position":   placement.Position,
+			"expires_at": placement.ExpiresAt,
+			"user_id":    placement.UserID,
+			"updated_at": now,
+			"deleted_at": nil,
+		}),
+	}).Create(&placement).Error
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "设置首页推荐失败"})
+		return
+	}
+
+	if err := database.DB.Where("post_id = ? AND section = ?", postID, section).First(&placement).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "设置首页推荐失败"})
+		return
+	}
+	c.JSON(http.StatusOK, placement)
+}
+
+// RemoveHomePlacement 将文章移出首页推荐位（管理员）
+// 参数: c - Gin上下文
+func RemoveHomePlacement(c *gin.Context) {
+	section, postID, ok := parsePlacementTarget(c)
+	if !ok {
+		return
+	}
+
+	result := database.DB.Where("post_id = ? AND section = ?", postID, section).Delete(&models.HomePlacement{})
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "移除首页推荐失败"})
+		return
+	}
+	if result.RowsAffected == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "文章不在该推荐位中"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "已移出首页推荐"})
+}
+
+// parsePlacementTarget 解析推荐位和文章ID，失败时直接写入错误响应
+// 参数: c - Gin上下文
+// 返回值: 推荐位, 文章ID, 是否成功
+func parsePlacementTarget(c *gin.Context) (string, uint, bool) {
+	section := c.Param("section")
+	if !models.ValidHomeSection(section) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "section must be pinned or featured"})
+		return "", 0, false
+	}
+	postID, err := strconv.ParseUint(c.Param("post_id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的文章ID"})
+		return "", 0, false
+	}
+	return section, uint(postID), true
+}
+
+// loadPlacedPosts 按排序加载推荐位中未过期且公开列出的文章
+// 参数: section - 推荐位, now - 当前时间, shown - 已在前面区块中出现的文章，加载的文章会加入其中
+// 返回值: 文章列表, 错误信息
+func loadPlacedPosts(section string, now time.Time, shown map[uint]bool) ([]models.Post, error) {
+	var postIDs []uint
+	err := database.DB.Model(&models.HomePlacement{}).
+		Where("section = ? AND (expires_at IS NULL OR expires_at > ?)", section, now).
+		Order("position, id").
+		Pluck("post_id", &postIDs).Error
+	if err != nil || len(postIDs) == 0 {
+		return []models.Post{}, err
+	}
+
+	var posts []models.Post
+	if err := database.DB.Preload("Tags").Preload("Category").Scopes(listedPosts).
+		Where("posts.id IN ?", postIDs).Find(&posts).Error; err != nil {
+		return nil, err
+	}
+	byID := make(map[uint]models.Post, len(posts))
+	for _, post := range posts {
+		byID[post.ID] = post
+	}
+
+	ordered := make([]models.Post, 0, len(posts))
+	for _, id := range postIDs {
+		post, ok := byID[id]
+		if !ok || shown[id] {
+			continue
+		}
+		shown[id] = true
+		ordered = append(ordered, post)
+	}
+	return ordered, nil
+}
@@ -0,0 +1,308 @@
+package controllers
+
+import (
+	"blog-system/config"
+	"blog-system/database"
+	"blog-system/models"
+	"blog-system/storage"
+	"blog-system/utils"
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"io"
+	"log"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"time"
+)
+
+// multipartOverhead 为 multipart 边界和其他表单字段预留的请求体大小
+const multipartOverhead = 1 << 20
+
+// UploadMedia 上传图片
+// 表单字段: file - 图片文件, post_id - 可选，关联的文章ID
+// 参数: c - Gin上下文
+func UploadMedia(c *gin.Context) {
+	userId, ok := currentUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+
+	maxSize := config.LoadConfig().UploadMaxSize
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxSize+multipartOverhead)
+
+	file, header, err := c.Request.FormFile("file")
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": fmt.Sprintf("文件不能超过 %d MB", maxSize>>20)})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": "file is required"})
+		return
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(io.LimitReader(file, maxSize+1))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "读取文件失败"})
+		return
+	}
+	if int64(len(data)) > maxSize {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": fmt.Sprintf("文件不能超过 %d MB", maxSize>>20)})
+		return
+	}
+
+	media := models.Media{UserID: userId, FileName: filepath.Base(header.Filename)}
+	if raw := c.PostForm("post_id"); raw != "" {
+		postID, err := strconv.ParseUint(raw, 10, 32)
+		if err != nil || postID == 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "无效的文章ID"})
+			return
+		}
+		id := uint(postID)
+		if status, err := checkMediaPost(id, userId); err != nil {
+			c.JSON(status, gin.H{"error": err.Error()})
+			return
+		}
+		media.PostID = &id
+	}
+
+	// 嗅探类型、去除元数据并生成缩略图
+	processed, err := utils.ProcessImage(data)
+	if err != nil {
+		if errors.Is(err, utils.ErrUnsupportedImage) {
+			c.JSON(http.StatusUnsupportedMediaType, gin.H{"error": "只支持 JPEG、PNG 和 GIF 图片"})
+		} else {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "无法解析图片: " + err.Error()})
+		}
+		return
+	}
+
+	name, err := randomName()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "生成文件名失败"})
+		return
+	}
+	dir := "media/" + time.Now().Format("2006/01")
+	media.StorageKey = dir + "/" + name + processed.Ext
+	media.ThumbnailKey = dir + "/" + name + "_thumb" + thumbnailExt(processed.ThumbnailMime)
+	media.MimeType = processed.MimeType
+	media.Size = int64(len(processed.Data))
+	media.Width, media.Height = processed.Width, processed.Height
+
+	ctx := c.Request.Context()
+	if err := storage.Default.Save(ctx, media.StorageKey, bytes.NewReader(processed.Data), processed.MimeType); err != nil {
+		log.Printf("保存文件失败: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "保存文件失败"})
+		return
+	}
+	if err := storage.Default.Save(ctx, media.ThumbnailKey, bytes.NewReader(processed.Thumbnail), processed.ThumbnailMime); err != nil {
+		log.Printf("保存缩略图失败: %v", err)
+		removeMediaFiles(&media)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "保存文件失败"})
+		return
+	}
+
+	if err := database.DB.Create(&media).Error; err != nil {
+		removeMediaFiles(&media)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "保存媒体记录失败"})
+		return
+	}
+
+	fillMediaURLs(&media)
+	c.JSON(http.StatusCreated, media)
+}
+
+// GetMyMedia 获取当前用户上传的媒体文件
+// 查询参数: post_id - 可选，只返回关联到该文章的文件
+// 参数: c - Gin上下文
+func GetMyMedia(c *gin.Context) {
+	userId, ok := currentUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+	page, pageSize := parsePagination(c)
+
+	query := database.DB.Model(&models.Media{}).Where("user_id = ?", userId)
+	if raw := c.Query("post_id"); raw != "" {
+		postID, err := strconv.ParseUint(raw, 10, 32)
+		if err != nil || postID == 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "无效的文章ID"})
+			return
+		}
+		query = query.Where("post_id = ?", postID)
+	}
+
+	var total int64
+	if err := query.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "获取媒体列表失败"})
+		return
+	}
+
+	var items []models.Media
+	if err := query.Order("id DESC").Offset((page - 1) * pageSize).Limit(pageSize).Find(&items).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "获取媒体列表失败"})
+		return
+	}
+	for i := range items {
+		fillMediaURLs(&items[i])
+	}
+
+	c.JSON(http.StatusOK, gin.H{"total": total, "page": page, "page_size": pageSize, "items": items})
+}
+
+// UpdateMedia 修改媒体文件关联的文章，post_id 为 null 时解除关联
+// 参数: c - Gin上下文
+func UpdateMedia(c *gin.Context) {
+	media, ok := loadOwnedMedia(c)
+	if !ok {
+		return
+	}
+
+	var data map[string]interface{}
+	if err := c.ShouldBindJSON(&data); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	raw, exists := data["post_id"]
+	if !exists {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "post_id is required"})
+		return
+	}
+	postID, err := parseOptionalID(raw)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "post_id " + err.Error()})
+		return
+	}
+	if postID != nil {
+		if status, err := checkMediaPost(*postID, media.UserID); err != nil {
+			c.JSON(status, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	if err := database.DB.Model(media).Update("post_id", postID).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "更新媒体失败"})
+		return
+	}
+	media.PostID = postID
+
+	fillMediaURLs(media)
+	c.JSON(http.StatusOK, media)
+}
+
+// DeleteMedia 删除媒体文件及其存储内容
+// 参数: c - Gin上下文
+func DeleteMedia(c *gin.Context) {
+	media, ok := loadOwnedMedia(c)
+	if !ok {
+		return
+	}
+
+	if err := database.DB.Unscoped().Delete(media).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "删除媒体失败"})
+		return
+	}
+	removeMediaFiles(media)
+
+	c.JSON(http.StatusOK, gin.H{"message": "媒体删除成功"})
+}
+
+// loadOwnedMedia 加载当前用户拥有的媒体文件，失败时直接写入错误响应
+// 参数: c - Gin上下文
+// 返回值: 媒体记录, 是否成功
+func loadOwnedMedia(c *gin.Context) (*models.Media, bool) {
+	userId, ok := currentUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return nil, false
+	}
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的媒体ID"})
+		return nil, false
+	}
+
+	var media models.Media
+	if err := database.DB.First(&media, id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "媒体不存在"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		}
+		return nil, false
+	}
+	if media.UserID != userId {
+		c.JSON(http.StatusForbidden, gin.H{"error": "You are not the owner of this media"})
+		return nil, false
+	}
+	return &media, true
+}
+
+// checkMediaPost 检查用户是否可以把媒体关联到文章，需要文章的编辑权限
+// 参数: postID - 文章ID, userId - 用户ID
+// 返回值: 失败时的HTTP状态码, 错误信息
+func checkMediaPost(postID, userId uint) (int, error) {
+	var post models.Post
+	if err := database.DB.Select("id", "user_id").First(&post, postID).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return http.StatusBadRequest, errors.New("文章不存在")
+		}
+		return http.StatusInternalServerError, errors.New("Database error")
+	}
+	role, err := post.RoleOf(database.DB, userId)
+	if err != nil {
+		return http.StatusInternalServerError, errors.New("Database error")
+	}
+	if !models.RoleAtLeast(role, models.CollaboratorCoAuthor) {
+		return http.StatusForbidden, errors.New("You are not allowed to edit this post")
+	}
+	return 0, nil
+}
+
+// fillMediaURLs 根据存储后端填充访问地址
+func fillMediaURLs(media *models.Media) {
+	media.URL = storage.Default.URL(media.StorageKey)
+	if media.ThumbnailKey != "" {
+		media.ThumbnailURL = storage.Default.URL(media.ThumbnailKey)
+	}
+}
+
+// removeMediaFiles 删除媒体文件在存储后端中的内容，失败只记录日志
+func removeMediaFiles(media *models.Media) {
+	ctx := context.Background()
+	for _, key := range []string{media.StorageKey, media.ThumbnailKey} {
+		if key == "" {
+			continue
+		}
+		if err := storage.Default.Delete(ctx, key); err != nil {
+			log.Printf("删除文件 %s 失败: %v", key, err)
+		}
+	}
+}
+
+// randomName 生成随机文件名
+func randomName() (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+// thumbnailExt 根据缩略图 MIME 类型返回扩展名
+func thumbnailExt(mimeType string) string {
+	if mimeType == "image/jpeg" {
+		return ".jpg"
+	}
+	return ".png"
+}
@@ -0,0 +1,306 @@
+package controllers
+
+import (
+	"blog-system/database"
+	"blog-system/models"
+	"blog-system/utils"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// maxMentionedUsers 每条内容最多提及的用户数，超出的提及会被忽略
+const maxMentionedUsers = 50
+
+// mentionItem 提及我的内容
+type mentionItem struct {
+	SourceType  string              `json:"source_type"`      // 内容类型：post 或 comment
+	SourceID    uint                `json:"source_id"`        // 内容ID
+	PostID      uint                `json:"post_id"`          // 所属文章ID
+	PostTitle   string              `json:"post_title"`       // 所属文章标题
+	Author      *models.UserSummary `json:"author,omitempty"` // 提及者，游客评论为游客昵称
+	Snippet     string              `json:"snippet"`          // 包含提及的内容片段（HTML，提及已高亮）
+	MentionedAt time.Time           `json:"mentioned_at"`     // 提及时间（内容最后一次保存的时间）
+}
+
+// mentionRow 提及查询的原始结果
+type mentionRow struct {
+	SourceType string
+	SourceID   uint
+	PostID     uint
+	LatestID   uint // 该内容中最新一条提及记录的ID
+}
+
+// GetMentions 获取提及当前用户的文章和评论，按时间倒序
+// 只返回当前用户可以查看的内容，不包括自己提及自己
+// 查询参数: page/page_size - 分页
+// 参数: c - Gin上下文
+func GetMentions(c *gin.Context) {
+	userId, ok := currentUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+	page, pageSize := parsePagination(c)
+
+	// 文章对当前用户可见：已发布且公开或不公开列出，或者当前用户是作者或协作者
+	collaborations := database.DB.Model(&models.PostCollaborator{}).Select("post_id").Where("user_id = ?", userId)
+	query := database.DB.Model(&models.Mention{}).
+		Joins("JOIN posts ON posts.id = mentions.post_id AND posts.deleted_at IS NULL").
+		Joins("LEFT JOIN comments ON mentions.source_type = ? AND comments.id = mentions.source_id", models.MentionSourceComment).
+		Where("mentions.user_id = ? AND (mentions.author_id IS NULL OR mentions.author_id <> ?)", userId, userId).
+		Where("mentions.source_type = ? OR (comments.deleted_at IS NULL AND comments.status = ? AND comments.hidden_at IS NULL)",
+			models.MentionSourcePost, models.CommentStatusApproved).
+		Where("(posts.status = ? AND posts.hidden_at IS NULL AND posts.visibility IN ?) OR posts.user_id = ? OR posts.id IN (?)",
+			models.PostStatusPublished, []string{models.VisibilityPublic, models.VisibilityUnlisted}, userId, collaborations).
+		Group("mentions.source_type, mentions.source_id, mentions.post_id")
+
+	var total int64
+	if err := database.DB.Table("(?) AS sources", query.Session(&gorm.Session{}).Select("mentions.source_type, mentions.source_id")).
+		Count(&total).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "获取提及失败"})
+		return
+	}
+
+	var rows []mentionRow
+	// 提及记录随内容保存整体重建，ID 越大说明提及越新；不直接取 MAX(created_at)，以便 SQLite 驱动按列类型解析时间
+	if err := query.Select("mentions.source_type, mentions.source_id, mentions.post_id, MAX(mentions.id) AS latest_id").
+		Order("latest_id DESC").
+		Offset((page - 1) * pageSize).Limit(pageSize).
+		Scan(&rows).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "获取提及失败"})
+		return
+	}
+
+	items, err := buildMentionItems(rows, userId)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "获取提及失败"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"total":     total,
+		"page":      page,
+		"page_size": pageSize,
+		"items":     items,
+	})
+}
+
+// buildMentionItems 批量加载提及所在的文章和评论，生成包含提及的片段
+// 参数: rows - 提及查询结果, userID - 被提及的用户ID
+// 返回值: 提及列表, 错误信息
+func buildMentionItems(rows []mentionRow, userID uint) ([]mentionItem, error) {
+	items := make([]mentionItem, 0, len(rows))
+	if len(rows) == 0 {
+		return items, nil
+	}
+
+	var user models.User
+	if err := database.DB.Select("id", "username").First(&user, userID).Error; err != nil {
+		return nil, err
+	}
+	terms := []string{"@" + user.Username}
+
+	var postIDs, commentIDs, latestIDs []uint
+	for _, row := range rows {
+		postIDs = append(postIDs, row.PostID)
+		latestIDs = append(latestIDs, row.LatestID)
+		if row.SourceType == models.MentionSourceComment {
+			commentIDs = append(commentIDs, row.SourceID)
+		}
+	}
+	var posts []models.Post
+	if err := database.DB.Select("id", "user_id", "title", "content").Where("id IN ?", postIDs).Find(&posts).Error; err != nil {
+		return nil, err
+	}
+	postsByID := make(map[uint]*models.Post, len(posts))
+	for i := range posts {
+		postsByID[posts[i].ID] = &posts[i]
+	}
+	var comments []models.Comment
+	if len(commentIDs) > 0 {
+		if err := database.DB.Select("id", "user_id", "guest_name", "content").Where("id IN ?", commentIDs).Find(&comments).Error; err != nil {
+			return nil, err
+		}
+	}
+	commentsByID := make(map[uint]*models.Comment, len(comments))
+	commentPointers := make([]*models.Comment, len(comments))
+	for i := range comments {
+		commentsByID[comments[i].ID] = &comments[i]
+		commentPointers[i] = &comments[i]
+	}
+	if err := fillCommentAuthors(commentPointers); err != nil {
+		return nil, err
+	}
+	postAuthors, err := postAuthorSummaries(posts)
+	if err != nil {
+		return nil, err
+	}
+	var latest []models.Mention
+	if err := database.DB.Select("id", "created_at").Where("id IN ?", latestIDs).Find(&latest).Error; err != nil {
+		return nil, err
+	}
+	mentionedAt := make(map[uint]time.Time, len(latest))
+	for _, mention := range latest {
+		mentionedAt[mention.ID] = mention.CreatedAt
+	}
+
+	for _, row := range rows {
+		post, ok := postsByID[row.PostID]
+		if !ok {
+			continue
+		}
+		item := mentionItem{SourceType: row.SourceType, SourceID: row.SourceID, PostID: row.PostID, PostTitle: post.Title, MentionedAt: mentionedAt[row.LatestID]}
+		if row.SourceType == models.MentionSourceComment {
+			comment, ok := commentsByID[row.SourceID]
+			if !ok {
+				continue
+			}
+			item.Author, item.Snippet = comment.Author, utils.Snippet(comment.Content, terms, snippetWidth)
+		} else {
+			item.Author, item.Snippet = postAuthors[post.UserID], utils.Snippet(post.Content, terms, snippetWidth)
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+// postAuthorSummaries 一次查询加载文章作者的摘要
+func postAuthorSummaries(posts []models.Post) (map[uint]*models.UserSummary, error) {
+	ids := make([]uint, 0, len(posts))
+	for _, post := range posts {
+		ids = append(ids, post.UserID)
+	}
+	var users []models.User
+	if err := database.DB.Select("id", "username", "email").Where("id IN ?", ids).Find(&users).Error; err != nil {
+		return nil, err
+	}
+	summaries := make(map[uint]*models.UserSummary, len(users))
+	for _, user := range users {
+		summaries[user.ID] = &models.UserSummary{ID: user.ID, Username: user.Username, Avatar: utils.AvatarURL(user.Email)}
+	}
+	return summaries, nil
+}
+
+// saveMentions 解析内容中的 @提及并重建该内容的提及记录，不存在的用户名会被忽略
+// 参数: tx - 数据库事务, sourceType - 内容类型, sourceID - 内容ID, postID - 所属文章ID,
+// authorID - 提及者ID（游客为空）, content - 内容
+// 返回值: 内容中的提及, 错误信息
+func saveMentions(tx *gorm.DB, sourceType string, sourceID, postID uint, authorID *uint, content string) ([]models.MentionRange, error) {
+	if err := tx.Where("source_type = ? AND source_id = ?", sourceType, sourceID).Delete(&models.Mention{}).Error; err != nil {
+		return nil, err
+	}
+	spans := utils.ParseMentions(content)
+	if len(spans) == 0 {
+		return nil, nil
+	}
+
+	// 查询时按数据库的排序规则匹配用户名（MySQL 默认不区分大小写），结果按小写对应回提及
+	var names []string
+	seen := make(map[string]bool)
+	for _, span := range spans {
+		key := strings.ToLower(span.Username)
+		if !seen[key] && len(names) < maxMentionedUsers {
+			seen[key] = true
+			names = append(names, span.Username)
+		}
+	}
+	var users []models.User
+	if err := tx.Select("id", "username").Where("username IN ?", names).Find(&users).Error; err != nil {
+		return nil, err
+	}
+	byName := make(map[string]models.User, len(users))
+	for _, user := range users {
+		byName[strings.ToLower(user.Username)] = user
+	}
+
+	var mentions []models.Mention
+	var ranges []models.MentionRange
+	for _, span := range spans {
+		user, ok := byName[strings.ToLower(span.Username)]
+		if !ok {
+			continue
+		}
+		mentions = append(mentions, models.Mention{
+			SourceType: sourceType, SourceID: sourceID, PostID: postID, UserID: user.ID, AuthorID: authorID,
+			Start: span.Start, End: span.End,
+		})
+		ranges = append(ranges, models.MentionRange{UserID: user.ID, Username: user.Username, Start: span.Start, End: span.End})
+	}
+	if len(mentions) == 0 {
+		return nil, nil
+	}
+	return ranges, tx.Create(&mentions).Error
+}
+
+// loadMentions 一次查询加载多条内容中的提及
+// 参数: sourceType - 内容类型, ids - 内容ID
+// 返回值: 按内容ID分组的提及, 错误信息
+func loadMentions(sourceType string, ids []uint) (map[uint][]models.MentionRange, error) {
+	result := make(map[uint][]models.MentionRange)
+	if len(ids) == 0 {
+		return result, nil
+	}
+	var rows []struct {
+		SourceID uint
+		UserID   uint
+		Username string
+		StartPos int
+		EndPos   int
+	}
+	err := database.DB.Model(&models.Mention{}).
+		Select("mentions.source_id, mentions.user_id, users.username, mentions.start_pos, mentions.end_pos").
+		Joins("JOIN users ON users.id = mentions.user_id").
+		Where("mentions.source_type = ? AND mentions.source_id IN ?", sourceType, ids).
+		Order("mentions.source_id, mentions.start_pos").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		result[row.SourceID] = append(result[row.SourceID], models.MentionRange{
+			UserID: row.UserID, Username: row.Username, Start: row.StartPos, End: row.EndPos,
+		})
+	}
+	return result, nil
+}
+
+// fillPostMentions 为文章填充正文中的提及，只返回摘要的文章不填充
+// 参数: posts - 文章列表
+func fillPostMentions(posts []*models.Post) error {
+	ids := make([]uint, 0, len(posts))
+	for _, post := range posts {
+		if !post.Locked {
+			ids = append(ids, post.ID)
+		}
+	}
+	mentions, err := loadMentions(models.MentionSourcePost, ids)
+	if err != nil {
+		return err
+	}
+	for _, post := range posts {
+		if !post.Locked {
+			post.Mentions = mentions[post.ID]
+		}
+	}
+	return nil
+}
+
+// fillCommentMentions 为评论填充内容中的提及
+// 参数: comments - 评论列表
+func fillCommentMentions(comments []*models.Comment) error {
+	ids := make([]uint, 0, len(comments))
+	for _, comment := range comments {
+		ids = append(ids, comment.ID)
+	}
+	mentions, err := loadMentions(models.MentionSourceComment, ids)
+	if err != nil {
+		return err
+	}
+	for _, comment := range comments {
+		comment.Mentions = mentions[comment.ID]
+	}
+	return nil
+}
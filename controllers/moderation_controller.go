@@ -0,0 +1,304 @@
+package controllers
+
+import (
+	"blog-system/config"
+	"blog-system/database"
+	"blog-system/models"
+	"blog-system/spam"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// moderationItem 审核队列中的评论，附带垃圾评论检查结果
+type moderationItem struct {
+	models.Comment
+	SpamScore   float64 `json:"spam_score"`
+	SpamReasons string  `json:"spam_reasons"`
+}
+
+// GetModerationQueue 获取当前用户可以审核的评论
+// 管理员可以审核全部评论，其他用户只能审核自己作为所有者的文章下的评论
+// 查询参数: status - pending（默认）、approved 或 rejected, post_id - 可选，只看某篇文章, page/page_size - 分页
+// 参数: c - Gin上下文
+func GetModerationQueue(c *gin.Context) {
+	userId, ok := currentUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+	status := c.DefaultQuery("status", models.CommentStatusPending)
+	if status != models.CommentStatusPending && status != models.CommentStatusApproved && status != models.CommentStatusRejected {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "status must be pending, approved or rejected"})
+		return
+	}
+	page, pageSize := parsePagination(c)
+
+	query, err := moderatedComments(userId)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "获取审核队列失败"})
+		return
+	}
+	query = query.Where("status = ?", status)
+	if raw := c.Query("post_id"); raw != "" {
+		postID, err := strconv.ParseUint(raw, 10, 32)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "无效的文章ID"})
+			return
+		}
+		query = query.Where("post_id = ?", postID)
+	}
+
+	var total int64
+	if err := query.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "获取审核队列失败"})
+		return
+	}
+
+	// 待审核的评论先进先出，已处理的评论按处理时间倒序
+	order := "created_at, id"
+	if status != models.CommentStatusPending {
+		order = "updated_at DESC, id DESC"
+	}
+	var comments []models.Comment
+	if err := query.Order(order).Offset((page - 1) * pageSize).Limit(pageSize).Find(&comments).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "获取审核队列失败"})
+		return
+	}
+
+	items := make([]moderationItem, len(comments))
+	for i, comment := range comments {
+		items[i] = moderationItem{Comment: comment, SpamScore: comment.SpamScore, SpamReasons: comment.SpamReasons}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"total":     total,
+		"page":      page,
+		"page_size": pageSize,
+		"items":     items,
+	})
+}
+
+// ModerateComments 批量审核评论
+// 请求体: ids - 评论ID数组, action - approve 或 reject
+// 所有评论都必须可由当前用户审核，否则不做任何修改
+// 参数: c - Gin上下文
+func ModerateComments(c *gin.Context) {
+	userId, ok := currentUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+
+	var data map[string]interface{}
+	if err := c.ShouldBindJSON(&data); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	var status string
+	switch data["action"] {
+	case "approve":
+		status = models.CommentStatusApproved
+	case "reject":
+		status = models.CommentStatusRejected
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "action must be approve or reject"})
+		return
+	}
+	ids, err := parseIDList(data["ids"])
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ids " + err.Error()})
+		return
+	}
+	if len(ids) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ids is required"})
+		return
+	}
+
+	query, err := moderatedComments(userId)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "审核评论失败"})
+		return
+	}
+	var allowed int64
+	if err := query.Where("id IN ?", ids).Count(&allowed).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "审核评论失败"})
+		return
+	}
+	if allowed != int64(len(ids)) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "部分评论不存在或无权审核"})
+		return
+	}
+
+	// 管理员的审核结果同时用于训练全站共用的垃圾评论分类器：通过视为正常评论，拒绝视为垃圾评论；
+	// 文章所有者的审核只影响自己的文章，不参与训练
+	trains, err := isAdmin(userId)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "审核评论失败"})
+		return
+	}
+	label := models.SpamLabelHam
+	if status == models.CommentStatusRejected {
+		label = models.SpamLabelSpam
+	}
+	var updated int64
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.Comment{}).Where("id IN ? AND status <> ?", ids, status).
+			Updates(map[string]interface{}{"status": status, "updated_at": time.Now()})
+		if result.Error != nil {
+			return result.Error
+		}
+		updated = result.RowsAffected
+		if !trains {
+			return nil
+		}
+
+		// 审核者本人以及文章作者和协作者的评论不参与训练
+		var comments []models.Comment
+		if err := tx.Select("id", "content", "trained_as").Where("id IN ?", ids).
+			Where("comments.user_id IS NULL OR (comments.user_id <> ?"+
+				" AND comments.user_id NOT IN (SELECT posts.user_id FROM posts WHERE posts.id = comments.post_id)"+
+				" AND comments.user_id NOT IN (SELECT post_collaborators.user_id FROM post_collaborators WHERE post_collaborators.post_id = comments.post_id))", userId).
+			Find(&comments).Error; err != nil {
+			return err
+		}
+		for _, comment := range comments {
+			if err := spam.Learn(tx, comment.Content, comment.TrainedAs, label); err != nil {
+				return err
+			}
+			if err := tx.Model(&comment).UpdateColumn("trained_as", label).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "审核评论失败"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"status": status, "updated": updated})
+}
+
+// moderatedComments 构造当前用户可以审核的评论查询
+// 参数: userID - 当前用户ID
+// 返回值: 评论查询, 错误信息
+func moderatedComments(userID uint) (*gorm.DB, error) {
+	query := database.DB.Model(&models.Comment{})
+	admin, err := isAdmin(userID)
+	if err != nil || admin {
+		return query, err
+	}
+
+	owned := database.DB.Model(&models.PostCollaborator{}).Select("post_id").
+		Where("user_id = ? AND role = ?", userID, models.CollaboratorOwner)
+	posts := database.DB.Model(&models.Post{}).Select("id").Where("user_id = ? OR id IN (?)", userID, owned)
+	return query.Where("post_id IN (?)", posts), nil
+}
+
+// screenComment 根据审核模式和垃圾评论检查结果确定新评论的状态
+// 文章的协作者和管理员的评论无需审核，也不做垃圾评论检查；游客评论不论审核模式都需要审核
+// 参数: post - 文章, comment - 新评论（会写入状态和垃圾评论得分）, submission - 垃圾评论检查的输入
+// 返回值: 错误信息
+func screenComment(post *models.Post, comment *models.Comment, submission *spam.Submission) error {
+	comment.Status = models.CommentStatusApproved
+	if comment.UserID == nil {
+		comment.Status = models.CommentStatusPending
+	} else {
+		userID := *comment.UserID
+		role, err := post.RoleOf(database.DB, userID)
+		if err != nil {
+			return err
+		}
+		admin, err := isAdmin(userID)
+		if err != nil {
+			return err
+		}
+		if role != "" || admin {
+			return nil
+		}
+
+		switch commentModeration(post) {
+		case models.ModerationAll:
+			comment.Status = models.CommentStatusPending
+		case models.ModerationFirstTime:
+			var approved int64
+			if err := database.DB.Model(&models.Comment{}).
+				Where("user_id = ? AND status = ?", userID, models.CommentStatusApproved).
+				Count(&approved).Error; err != nil {
+				return err
+			}
+			if approved == 0 {
+				comment.Status = models.CommentStatusPending
+			}
+		}
+	}
+
+	// 得分达到阈值时转入人工审核，得分过高时直接拒绝
+	cfg := config.LoadConfig()
+	verdict := spam.Default.Check(submission)
+	comment.SpamScore, comment.SpamReasons = verdict.Score, verdict.Reasons()
+	switch {
+	case verdict.Score >= cfg.SpamRejectScore:
+		comment.Status = models.CommentStatusRejected
+	case verdict.Score >= cfg.SpamModerateScore && comment.Status == models.CommentStatusApproved:
+		comment.Status = models.CommentStatusPending
+	}
+	return nil
+}
+
+// screenEdit 按与新评论相同的规则检查编辑后的评论
+// 审核模式为 all 或垃圾评论得分达到阈值时，已通过的评论重新转入审核；编辑不会让待审核或被拒绝的评论通过
+// 参数: post - 文章, comment - 编辑后的评论（会写入状态和垃圾评论得分）, submission - 垃圾评论检查的输入
+// 返回值: 错误信息
+func screenEdit(post *models.Post, comment *models.Comment, submission *spam.Submission) error {
+	previous := comment.Status
+	if err := screenComment(post, comment, submission); err != nil {
+		return err
+	}
+	if previous == models.CommentStatusRejected ||
+		(previous == models.CommentStatusPending && comment.Status == models.CommentStatusApproved) {
+		comment.Status = previous
+	}
+	return nil
+}
+
+// commentModeration 获取文章生效的评论审核模式，文章未设置时使用站点设置
+func commentModeration(post *models.Post) string {
+	if post.CommentModeration != "" {
+		return post.CommentModeration
+	}
+	mode := config.LoadConfig().CommentModeration
+	if !models.ValidModerationMode(mode) {
+		log.Printf("无效的评论审核模式 %q，按 all 处理", mode)
+		return models.ModerationAll
+	}
+	return mode
+}
+
+// parseModerationMode 解析请求中的评论审核模式，空字符串表示使用站点设置
+// 参数: raw - 请求中的值
+// 返回值: 审核模式, 错误信息
+func parseModerationMode(raw interface{}) (string, error) {
+	mode, ok := raw.(string)
+	if !ok || (mode != "" && !models.ValidModerationMode(mode)) {
+		return "", errors.New("comment_moderation must be off, first_time, all or an empty string")
+	}
+	return mode, nil
+}
+
+// isAdmin 判断用户是否为管理员
+func isAdmin(userID uint) (bool, error) {
+	var user models.User
+	if err := database.DB.Select("id", "role").First(&user, userID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return false, nil
+		}
+		return false, err
+	}
+	return user.Role == models.RoleAdmin, nil
+}
@@ -2,11 +2,18 @@ package controllers
 
 import (
 	"blog-system/database"
+	"blog-system/jobs"
 	"blog-system/models"
+	"blog-system/utils"
+	"errors"
+	"fmt"
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
 	"log"
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
 )
 
 // CreatePost 创建新文章
@@ -30,14 +37,91 @@ func CreatePost(c *gin.Context) {
 		return
 	}
 
-	// 内容类型断言和错误处理
+	// 内容类型断言和错误处理，同时渲染 Markdown 缓存
 	if content, ok := data["content"].(string); ok {
-		post.Content = content
+		contentHTML, toc, err := utils.RenderMarkdown(content)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Markdown 渲染失败"})
+			return
+		}
+		post.Content, post.ContentHTML, post.TOC = content, contentHTML, toc
 	} else {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "content must be a string"})
 		return
 	}
 
+	// 标签可选，支持字符串数组
+	tagNames, err := parseTagNames(data["tags"])
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if len(tagNames) > maxTagsPerPost {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "too many tags"})
+		return
+	}
+
+	// 分类可选，每篇文章最多属于一个分类
+	if post.CategoryID, err = parseOptionalID(data["category_id"]); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "category_id " + err.Error()})
+		return
+	}
+
+	// 状态可选，默认直接发布
+	post.Status = models.PostStatusPublished
+	if raw, ok := data["status"]; ok {
+		if post.Status, err = parsePostStatus(raw); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+	if post.Status == models.PostStatusPublished {
+		now := time.Now()
+		post.PublishedAt = &now
+	}
+
+	// 可见性可选，默认公开；受密码保护时必须提供密码
+	visibility, err := parseVisibility(data, "")
+	if err != nil {
+		if isInputError(err) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "设置访问密码失败"})
+		}
+		return
+	}
+	post.Visibility = models.VisibilityPublic
+	if value, ok := visibility["visibility"].(string); ok {
+		post.Visibility = value
+	}
+	if hash, ok := visibility["password_hash"].(string); ok {
+		post.PasswordHash = hash
+	}
+
+	// 评论审核模式可选，默认使用站点设置
+	if raw, ok := data["comment_moderation"]; ok {
+		if post.CommentModeration, err = parseModerationMode(raw); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	// 评论策略，默认允许评论
+	if raw, ok := data["comment_policy"]; ok {
+		if post.CommentPolicy, err = parseCommentPolicy(raw); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	// 是否允许游客评论，默认不允许
+	if raw, ok := data["guest_comments"]; ok {
+		if post.GuestComments, ok = raw.(bool); !ok {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "guest_comments must be a boolean"})
+			return
+		}
+	}
+
 	// 从上下文中获取用户ID
 	if userIdValue, exists := c.Get("userid"); exists {
 		// 类型安全转换
@@ -51,12 +135,34 @@ func CreatePost(c *gin.Context) {
 		}
 	}
 
-	// 创建文章 - 添加错误处理
-	if err := database.DB.Create(&post).Error; err != nil {
+	// 创建文章及标签关联 - 添加错误处理
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		if post.CategoryID != nil {
+			if err := tx.First(&models.Category{}, *post.CategoryID).Error; err != nil {
+				return err
+			}
+		}
+		tags, err := resolveTags(tx, tagNames)
+		if err != nil {
+			return err
+		}
+		post.Tags = tags
+		if err := tx.Create(&post).Error; err != nil {
+			return err
+		}
+		post.Mentions, err = saveMentions(tx, models.MentionSourcePost, post.ID, post.ID, &post.UserID, post.Content)
+		return err
+	})
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "分类不存在"})
+		return
+	}
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "创建文章失败: " + err.Error()})
 		return
 	}
 
+	c.Header("ETag", postETag(&post))
 	c.JSON(http.StatusCreated, post)
 }
 
@@ -65,14 +171,57 @@ func CreatePost(c *gin.Context) {
 func GetPosts(c *gin.Context) {
 	// 查询所有文章
 	var posts []models.Post
+	query := database.DB.Model(&models.Post{}).Preload("Tags").Preload("Category").Scopes(listedPosts)
+
+	// 按标签、分类过滤
+	query, err := applyPostFilters(c, query)
+	if err != nil {
+		if isInputError(err) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "获取文章列表失败"})
+		}
+		return
+	}
 
 	// 添加数据库查询错误处理
-	if err := database.DB.Find(&posts).Error; err != nil {
+	if err := query.Find(&posts).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "获取文章列表失败"})
 		return
 	}
+	for i := range posts {
+		ensureRendered(&posts[i])
+		// 列表中受密码保护的文章只返回摘要
+		if posts[i].Visibility == models.VisibilityPassword {
+			lockPost(&posts[i])
+		}
+	}
 
-	c.JSON(http.StatusOK, posts)
+	// 反应统计及当前用户的反应、收藏状态
+	userId, _ := currentUserID(c)
+	interactedAt, err := fillInteractions(postPointers(posts), userId)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "获取文章列表失败"})
+		return
+	}
+	if err := fillPostMentions(postPointers(posts)); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "获取文章列表失败"})
+		return
+	}
+	c.Header("Vary", "Authorization")
+
+	lastModified, err := lastChange(&models.Post{}, "")
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "获取文章列表失败"})
+		return
+	}
+	taxonomyChangedAt, err := lastTaxonomyChange()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "获取文章列表失败"})
+		return
+	}
+	lastModified = latestTime(lastModified, interactedAt, taxonomyChangedAt)
+	respondCached(c, "", lastModified, posts)
 }
 
 // GetPost 获取单篇文章详情
@@ -88,12 +237,81 @@ func GetPost(c *gin.Context) {
 	}
 
 	// 查询文章 - 添加错误处理
-	if err := database.DB.Where("id = ?", id).First(&post).Error; err != nil {
+	if err := database.DB.Preload("Tags").Preload("Category").Preload("Media").Preload("Collaborators.User").Where("id = ?", id).First(&post).Error; err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "文章不存在"})
 		return
 	}
 
-	c.JSON(http.StatusOK, post)
+	// 草稿和私密文章对无权限的用户表现为不存在，受密码保护的文章未授权时只返回摘要
+	access, err := postAccess(c, &post)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+	if access == postAccessNone {
+		c.JSON(http.StatusNotFound, gin.H{"error": "文章不存在"})
+		return
+	}
+	c.Header("Vary", "Authorization, "+postAccessHeader)
+	if isRestrictedPost(&post) {
+		c.Header("Cache-Control", "private, no-cache")
+	}
+	if access == postAccessTeaser {
+		lockPost(&post)
+	} else {
+		ensureRendered(&post)
+	}
+	for i := range post.Media {
+		fillMediaURLs(&post.Media[i])
+	}
+
+	// 所属系列的上一篇/下一篇
+	nav, seriesUpdatedAt, err := loadSeriesNav(&post)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "获取系列信息失败"})
+		return
+	}
+	post.Series = nav
+
+	// 正文中的 @提及，只返回摘要时不返回
+	if err := fillPostMentions([]*models.Post{&post}); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "获取提及信息失败"})
+		return
+	}
+
+	// 反应统计及当前用户的反应、收藏状态
+	userId, _ := currentUserID(c)
+	interactedAt, err := fillInteractions([]*models.Post{&post}, userId)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "获取文章互动信息失败"})
+		return
+	}
+
+	// 当前用户的评论权限，评论自动关闭后以关闭时间作为最后修改时间
+	var closedAt time.Time
+	if access == postAccessFull {
+		if post.CommentAccess, err = commentAccess(&post, userId); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "获取评论权限失败"})
+			return
+		}
+		if closesAt := post.CommentAccess.ClosesAt; closesAt != nil && !time.Now().Before(*closesAt) {
+			closedAt = *closesAt
+		}
+	}
+
+	// 记录浏览量：只统计他人对已发布文章全文的访问，写入在后台异步完成
+	if access == postAccessFull && post.Status == models.PostStatusPublished && userId != post.UserID {
+		jobs.RecordView(post.ID, visitorKey(c))
+	}
+
+	taxonomyChangedAt, err := lastTaxonomyChange()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "获取文章失败"})
+		return
+	}
+
+	// ETag 以“文章ID-版本号”开头，可直接用于更新时的 If-Match
+	respondCached(c, postVersionTag(&post), latestTime(post.UpdatedAt, seriesUpdatedAt, interactedAt, closedAt, taxonomyChangedAt), post)
 }
 
 // UpdatePost 更新文章
@@ -107,6 +325,23 @@ func UpdatePost(c *gin.Context) {
 		return
 	}
 
+	// 乐观并发控制：必须携带 If-Match 指明基于哪个版本修改
+	ifMatch := c.GetHeader("If-Match")
+	if ifMatch == "" {
+		c.JSON(http.StatusPreconditionRequired, gin.H{"error": "If-Match header is required"})
+		return
+	}
+	// "*" 匹配任意版本，会绕过并发检查，因此要求指明具体版本
+	if strings.TrimSpace(ifMatch) == "*" {
+		c.JSON(http.StatusPreconditionRequired, gin.H{"error": "If-Match must specify the post version (ETag) being updated, * is not accepted"})
+		return
+	}
+	expectedVersions := parsePostETags(ifMatch, uint(id))
+	if len(expectedVersions) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid If-Match header"})
+		return
+	}
+
 	var data map[string]interface{} // 定义一个 map 接收 JSON 数据
 
 	// 绑定 JSON 数据到 map
@@ -126,39 +361,185 @@ func UpdatePost(c *gin.Context) {
 		return
 	}
 
-	// 内容类型断言和错误处理
+	// 内容类型断言和错误处理，内容变化时重新渲染 Markdown 缓存
 	if content, ok := data["content"].(string); ok {
+		contentHTML, toc, err := utils.RenderMarkdown(content)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Markdown 渲染失败"})
+			return
+		}
 		updateData["content"] = content
+		updateData["content_html"] = contentHTML
+		updateData["toc"] = toc
 	} else if data["content"] != nil { // 如果提供了content但不是字符串
 		c.JSON(http.StatusBadRequest, gin.H{"error": "content must be a string"})
 		return
 	}
 
+	// 标签：提供 tags 字段时整体替换，传空数组表示清空
+	_, updateTags := data["tags"]
+	tagNames, err := parseTagNames(data["tags"])
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if len(tagNames) > maxTagsPerPost {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "too many tags"})
+		return
+	}
+
+	// 分类：显式传 null 表示取消分类
+	if raw, ok := data["category_id"]; ok {
+		categoryID, err := parseOptionalID(raw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "category_id " + err.Error()})
+			return
+		}
+		if categoryID != nil {
+			if err := database.DB.First(&models.Category{}, *categoryID).Error; err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "分类不存在"})
+				return
+			}
+		}
+		updateData["category_id"] = categoryID
+	}
+
+	// 状态：首次发布时记录发布时间，撤回为草稿时保留原发布时间
+	if raw, ok := data["status"]; ok {
+		status, err := parsePostStatus(raw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		updateData["status"] = status
+		if status == models.PostStatusPublished {
+			updateData["published_at"] = gorm.Expr("COALESCE(published_at, ?)", time.Now())
+		}
+	}
+
+	// 可见性和访问密码
+	if _, ok := data["visibility"]; ok || data["password"] != nil {
+		var current models.Post
+		if err := database.DB.Select("visibility").First(&current, id).Error; err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "文章不存在"})
+			return
+		}
+		fields, err := parseVisibility(data, current.Visibility)
+		if err != nil {
+			if isInputError(err) {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			} else {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "设置访问密码失败"})
+			}
+			return
+		}
+		for key, value := range fields {
+			updateData[key] = value
+		}
+	}
+
+	// 评论审核模式，传空字符串表示恢复使用站点设置
+	if raw, ok := data["comment_moderation"]; ok {
+		mode, err := parseModerationMode(raw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		updateData["comment_moderation"] = mode
+	}
+
+	// 评论策略
+	if raw, ok := data["comment_policy"]; ok {
+		policy, err := parseCommentPolicy(raw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		updateData["comment_policy"] = policy
+	}
+
+	// 是否允许游客评论
+	if raw, ok := data["guest_comments"]; ok {
+		guestComments, ok := raw.(bool)
+		if !ok {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "guest_comments must be a boolean"})
+			return
+		}
+		updateData["guest_comments"] = guestComments
+	}
+
 	// 如果没有提供任何有效更新字段
-	if len(updateData) == 0 {
+	if len(updateData) == 0 && !updateTags {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "没有提供有效的更新字段"})
 		return
 	}
+	// 仅修改标签时也刷新更新时间，并递增版本号
+	updateData["updated_at"] = time.Now()
+	updateData["version"] = gorm.Expr("version + 1")
+
+	// 更新文章 - 添加错误处理，版本号不匹配时不会更新任何记录
+	var rowsAffected int64
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.Post{}).Where("id = ? AND version IN ?", id, expectedVersions).Updates(updateData)
+		if result.Error != nil {
+			return result.Error
+		}
+		rowsAffected = result.RowsAffected
+		if rowsAffected == 0 {
+			return nil
+		}
 
-	// 更新文章 - 添加错误处理
-	result := database.DB.Model(&models.Post{}).Where("id = ?", id).Updates(updateData)
-	if result.Error != nil {
+		// 正文变化时重建 @提及，提及者记为本次编辑的用户
+		if content, ok := updateData["content"].(string); ok {
+			editorID, _ := currentUserID(c)
+			if _, err := saveMentions(tx, models.MentionSourcePost, uint(id), uint(id), &editorID, content); err != nil {
+				return err
+			}
+		}
+		if !updateTags {
+			return nil
+		}
+
+		tags, err := resolveTags(tx, tagNames)
+		if err != nil {
+			return err
+		}
+		post := models.Post{Model: gorm.Model{ID: uint(id)}}
+		return tx.Model(&post).Association("Tags").Replace(tags)
+	})
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "更新文章失败"})
 		return
 	}
 
 	// 检查是否成功更新了记录
-	if result.RowsAffected == 0 {
-		c.JSON(http.StatusNotFound, gin.H{"error": "文章不存在或没有变化"})
+	if rowsAffected == 0 {
+		var current models.Post
+		if err := database.DB.Preload("Tags").Preload("Category").Preload("Collaborators.User").First(&current, id).Error; err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "文章不存在"})
+			return
+		}
+		// 版本已过期，返回当前版本供客户端合并
+		c.Header("ETag", postETag(&current))
+		c.JSON(http.StatusPreconditionFailed, gin.H{
+			"error":           "文章已被他人修改，请合并后重试",
+			"current_version": current.Version,
+			"current":         current,
+		})
 		return
 	}
 
 	var updatedPost models.Post
-	if err := database.DB.First(&updatedPost, id).Error; err != nil {
+	if err := database.DB.Preload("Tags").Preload("Category").Preload("Collaborators.User").First(&updatedPost, id).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "获取更新后的文章失败"})
+		return
+	}
+	if err := fillPostMentions([]*models.Post{&updatedPost}); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "获取更新后的文章失败"})
 		return
 	}
 
+	c.Header("ETag", postETag(&updatedPost))
 	c.JSON(http.StatusOK, updatedPost)
 }
 
@@ -173,18 +554,188 @@ func DeletePost(c *gin.Context) {
 		return
 	}
 
-	// 删除文章 - 添加错误处理
-	result := database.DB.Where("id = ?", id).Delete(&models.Post{})
-	if result.Error != nil {
+	// 软删除文章及其评论，移入回收站 - 添加错误处理
+	var rowsAffected int64
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		var err error
+		rowsAffected, err = trashPost(tx, uint(id))
+		return err
+	})
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "删除文章失败"})
 		return
 	}
 
 	// 检查是否成功删除了记录
-	if result.RowsAffected == 0 {
+	if rowsAffected == 0 {
 		c.JSON(http.StatusNotFound, gin.H{"error": "文章不存在"})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "文章删除成功"})
+	c.JSON(http.StatusOK, gin.H{"message": "文章已移入回收站"})
+}
+
+// trashPost 将文章及其评论移入回收站
+// 评论使用与文章相同的删除时间，恢复时据此只恢复随文章一起删除的评论
+// 参数: tx - 数据库事务, id - 文章ID
+// 返回值: 移入回收站的文章数（文章不存在或已删除时为 0）, 错误信息
+func trashPost(tx *gorm.DB, id uint) (int64, error) {
+	now := time.Now()
+	result := tx.Model(&models.Post{}).Where("id = ?", id).UpdateColumn("deleted_at", now)
+	if result.Error != nil || result.RowsAffected == 0 {
+		return result.RowsAffected, result.Error
+	}
+	return result.RowsAffected, tx.Model(&models.Comment{}).Where("post_id = ?", id).UpdateColumn("deleted_at", now).Error
+}
+
+// postVersionTag 生成由文章ID和版本号组成的标识
+// 参数: post - 文章
+// 返回值: 形如 "1-3" 的标识（不带引号）
+func postVersionTag(post *models.Post) string {
+	return fmt.Sprintf("%d-%d", post.ID, post.Version)
+}
+
+// postETag 生成文章的强 ETag
+// 参数: post - 文章
+// 返回值: 带引号的 ETag
+func postETag(post *models.Post) string {
+	return `"` + postVersionTag(post) + `"`
+}
+
+// parsePostETags 从 If-Match 头中解析客户端所基于的文章版本号
+// 读取接口返回的 ETag 在版本号后还带有内容哈希，这里只取前两段
+// 参数: header - If-Match 头（可包含多个 ETag）, id - 文章ID
+// 返回值: 版本号列表，无有效 ETag 时为空
+func parsePostETags(header string, id uint) []uint {
+	var versions []uint
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimSpace(tag)
+		// If-Match 使用强比较，弱 ETag 不可用
+		if len(tag) < 2 || tag[0] != '"' || tag[len(tag)-1] != '"' {
+			continue
+		}
+		parts := strings.SplitN(tag[1:len(tag)-1], "-", 3)
+		if len(parts) < 2 || parts[0] != strconv.FormatUint(uint64(id), 10) {
+			continue
+		}
+		if version, err := strconv.ParseUint(parts[1], 10, 32); err == nil && version > 0 {
+			versions = append(versions, uint(version))
+		}
+	}
+	return versions
+}
+
+// parsePostStatus 解析文章状态
+// 参数: raw - JSON 中的 status 字段
+// 返回值: 状态, 错误信息
+func parsePostStatus(raw interface{}) (string, error) {
+	status, ok := raw.(string)
+	if !ok || (status != models.PostStatusDraft && status != models.PostStatusPublished) {
+		return "", invalidInput("status must be draft or published")
+	}
+	return status, nil
+}
+
+// applyPostFilters 根据查询参数过滤文章
+// 支持 tags=go,web&match=any|all 按标签过滤，category_id=1&include_children=true 按分类过滤，author_id=1 按作者过滤
+// 参数: c - Gin上下文, query - 包含 posts 表的查询
+// 返回值: 过滤后的查询, 错误信息
+func applyPostFilters(c *gin.Context, query *gorm.DB) (*gorm.DB, error) {
+	tagNames, err := parseTagNames(c.Query("tags"))
+	if err != nil {
+		return nil, invalidInput("%s", err.Error())
+	}
+	if len(tagNames) > 0 {
+		match := c.DefaultQuery("match", "any")
+		if match != "any" && match != "all" {
+			return nil, invalidInput("match must be any or all")
+		}
+		query = applyTagFilter(query, tagNames, match == "all")
+	}
+
+	if raw := c.Query("category_id"); raw != "" {
+		categoryID, err := strconv.ParseUint(raw, 10, 32)
+		if err != nil || categoryID == 0 {
+			return nil, invalidInput("无效的分类ID")
+		}
+		categoryIDs := []uint{uint(categoryID)}
+		if c.Query("include_children") == "true" {
+			var categories []models.Category
+			if err := database.DB.Find(&categories).Error; err != nil {
+				return nil, err
+			}
+			categoryIDs = descendantCategoryIDs(categories, uint(categoryID))
+		}
+		query = query.Where("posts.category_id IN ?", categoryIDs)
+	}
+
+	if raw := c.Query("author_id"); raw != "" {
+		authorID, err := strconv.ParseUint(raw, 10, 32)
+		if err != nil || authorID == 0 {
+			return nil, invalidInput("无效的作者ID")
+		}
+		query = query.Where("posts.user_id = ?", authorID)
+	}
+
+	return query, nil
+}
+
+// fillInteractions 为文章填充反应统计以及当前用户的反应和收藏状态
+// 参数: posts - 文章列表, userID - 当前用户ID（0 表示未登录）
+// 返回值: 这些信息最近一次变化的时间, 错误信息
+func fillInteractions(posts []*models.Post, userID uint) (time.Time, error) {
+	if err := fillReactions(posts, userID); err != nil {
+		return time.Time{}, err
+	}
+	if err := fillBookmarked(posts, userID); err != nil {
+		return time.Time{}, err
+	}
+
+	reactedAt, err := lastReactionChange(posts)
+	if err != nil {
+		return time.Time{}, err
+	}
+	bookmarkedAt, err := lastBookmarkChange(posts, userID)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return latestTime(reactedAt, bookmarkedAt), nil
+}
+
+// lastTaxonomyChange 查询标签和分类最近一次变化的时间
+// 重命名、合并标签以及移动、删除分类都会改变文章响应中的 tags 和 category，但不会更新文章本身
+// 返回值: 最近一次变化的时间, 错误信息
+func lastTaxonomyChange() (time.Time, error) {
+	tagsChangedAt, err := lastChange(&models.Tag{}, "")
+	if err != nil {
+		return time.Time{}, err
+	}
+	categoriesChangedAt, err := lastChange(&models.Category{}, "")
+	if err != nil {
+		return time.Time{}, err
+	}
+	return latestTime(tagsChangedAt, categoriesChangedAt), nil
+}
+
+// ensureRendered 为尚未缓存 HTML 的旧文章补充渲染结果并回写数据库
+// 参数: post - 文章
+func ensureRendered(post *models.Post) {
+	if post.ContentHTML != "" || post.Content == "" {
+		return
+	}
+
+	contentHTML, toc, err := utils.RenderMarkdown(post.Content)
+	if err != nil {
+		log.Printf("渲染文章 %d 失败: %v", post.ID, err)
+		return
+	}
+	post.ContentHTML, post.TOC = contentHTML, toc
+
+	// 回写缓存不应改变文章的更新时间
+	if err := database.DB.Model(post).UpdateColumns(map[string]interface{}{
+		"content_html": contentHTML,
+		"toc":          toc,
+	}).Error; err != nil {
+		log.Printf("缓存文章 %d 渲染结果失败: %v", post.ID, err)
+	}
 }
@@ -0,0 +1,166 @@
+package controllers
+
+import (
+	"blog-system/database"
+	"blog-system/models"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm/clause"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// AddReaction 对文章添加一种反应，重复添加不会重复计数
+// 参数: c - Gin上下文
+func AddReaction(c *gin.Context) {
+	post, reactionType, ok := loadReactionTarget(c)
+	if !ok {
+		return
+	}
+	userId, _ := currentUserID(c)
+
+	// 唯一索引保证每人每种反应只有一条记录，已取消的反应会被恢复
+	now := time.Now()
+	reaction := models.Reaction{PostID: post.ID, UserID: userId, Type: reactionType, CreatedAt: now, UpdatedAt: now}
+	err := database.DB.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "post_id"}, {Name: "user_id"}, {Name: "type"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{"deleted_at": nil, "updated_at": now}),
+	}).Create(&reaction).Error
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "添加反应失败"})
+		return
+	}
+
+	respondReactions(c, post)
+}
+
+// RemoveReaction 取消对文章的一种反应
+// 参数: c - Gin上下文
+func RemoveReaction(c *gin.Context) {
+	post, reactionType, ok := loadReactionTarget(c)
+	if !ok {
+		return
+	}
+	userId, _ := currentUserID(c)
+
+	err := database.DB.Where("post_id = ? AND user_id = ? AND type = ?", post.ID, userId, reactionType).
+		Delete(&models.Reaction{}).Error
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "取消反应失败"})
+		return
+	}
+
+	respondReactions(c, post)
+}
+
+// loadReactionTarget 解析反应类型并加载当前用户可以访问的文章，失败时直接写入错误响应
+// 参数: c - Gin上下文
+// 返回值: 文章, 反应类型, 是否成功
+func loadReactionTarget(c *gin.Context) (*models.Post, string, bool) {
+	if _, ok := currentUserID(c); !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return nil, "", false
+	}
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的文章ID"})
+		return nil, "", false
+	}
+	reactionType := c.Param("type")
+	if !models.ValidReactionType(reactionType) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "不支持的反应类型", "types": models.ReactionTypes})
+		return nil, "", false
+	}
+
+	var post models.Post
+	if err := database.DB.Select("id", "user_id", "status", "visibility", "password_hash", "hidden_at").First(&post, id).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "文章不存在"})
+		return nil, "", false
+	}
+	if !checkPostAccess(c, &post) {
+		return nil, "", false
+	}
+	return &post, reactionType, true
+}
+
+// respondReactions 返回文章最新的反应统计
+func respondReactions(c *gin.Context, post *models.Post) {
+	userId, _ := currentUserID(c)
+	if err := fillReactions([]*models.Post{post}, userId); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "获取反应统计失败"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"post_id": post.ID, "reactions": post.Reactions, "my_reactions": post.MyReactions})
+}
+
+// fillReactions 为文章填充各类反应的数量以及当前用户的反应
+// 参数: posts - 文章列表, userID - 当前用户ID（0 表示未登录）
+func fillReactions(posts []*models.Post, userID uint) error {
+	if len(posts) == 0 {
+		return nil
+	}
+	ids := make([]uint, len(posts))
+	byID := make(map[uint]*models.Post, len(posts))
+	for i, post := range posts {
+		ids[i] = post.ID
+		byID[post.ID] = post
+		post.Reactions = map[string]int64{}
+		if userID != 0 {
+			post.MyReactions = []string{}
+		}
+	}
+
+	var counts []struct {
+		PostID uint
+		Type   string
+		Count  int64
+	}
+	err := database.DB.Model(&models.Reaction{}).
+		Select("post_id, type, COUNT(*) AS count").
+		Where("post_id IN ?", ids).
+		Group("post_id, type").
+		Scan(&counts).Error
+	if err != nil {
+		return err
+	}
+	for _, row := range counts {
+		byID[row.PostID].Reactions[row.Type] = row.Count
+	}
+
+	if userID == 0 {
+		return nil
+	}
+	var mine []models.Reaction
+	if err := database.DB.Select("post_id", "type").Where("post_id IN ? AND user_id = ?", ids, userID).
+		Order("id").Find(&mine).Error; err != nil {
+		return err
+	}
+	for _, reaction := range mine {
+		post := byID[reaction.PostID]
+		post.MyReactions = append(post.MyReactions, reaction.Type)
+	}
+	return nil
+}
+
+// lastReactionChange 查询文章反应最近一次变化的时间
+// 参数: posts - 文章列表
+// 返回值: 最近变化时间，没有反应时为零值
+func lastReactionChange(posts []*models.Post) (time.Time, error) {
+	if len(posts) == 0 {
+		return time.Time{}, nil
+	}
+	ids := make([]uint, len(posts))
+	for i, post := range posts {
+		ids[i] = post.ID
+	}
+	return lastChange(&models.Reaction{}, "post_id IN ?", ids)
+}
+
+// postPointers 返回指向切片中各文章的指针
+func postPointers(posts []models.Post) []*models.Post {
+	pointers := make([]*models.Post, len(posts))
+	for i := range posts {
+		pointers[i] = &posts[i]
+	}
+	return pointers
+}
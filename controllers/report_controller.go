@@ -0,0 +1,459 @@
+package controllers
+
+import (
+	"blog-system/config"
+	"blog-system/database"
+	"blog-system/models"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// maxReportDetailLength 举报说明的最大长度（字符数）
+const maxReportDetailLength = 1000
+
+// reportItem 审核队列中的举报，附带被举报内容的摘要
+type reportItem struct {
+	models.Report
+	Target      *reportTarget `json:"target"`       // 被举报的内容，已被永久删除时为空
+	OpenReports int64         `json:"open_reports"` // 该内容待处理的举报数
+}
+
+// reportTarget 被举报内容的摘要
+type reportTarget struct {
+	AuthorID *uint  `json:"author_id"`       // 作者ID，游客评论为空
+	PostID   uint   `json:"post_id"`         // 所属文章ID
+	Title    string `json:"title,omitempty"` // 文章标题，仅文章
+	Content  string `json:"content"`         // 内容
+	Hidden   bool   `json:"hidden"`          // 是否已被自动隐藏
+	Removed  bool   `json:"removed"`         // 是否已被删除
+}
+
+// CreateReport 举报文章或评论，每个用户对同一内容只能举报一次
+// 待处理的举报数达到阈值后内容会被自动隐藏，直到管理员驳回举报
+// 请求体: target_type - post 或 comment, target_id - 对象ID, category - 原因分类, detail - 说明（category 为 other 时必填）
+// 参数: c - Gin上下文
+func CreateReport(c *gin.Context) {
+	userId, ok := currentUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+
+	var data map[string]interface{}
+	if err := c.ShouldBindJSON(&data); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	targetType, _ := data["target_type"].(string)
+	if targetType != models.ReportTargetPost && targetType != models.ReportTargetComment {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "target_type must be post or comment"})
+		return
+	}
+	targetID, err := parseOptionalID(data["target_id"])
+	if err != nil || targetID == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "target_id must be a positive integer"})
+		return
+	}
+	category, _ := data["category"].(string)
+	if !models.ValidReportCategory(category) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "不支持的举报原因", "categories": models.ReportCategories})
+		return
+	}
+	detail, ok := data["detail"].(string)
+	if !ok && data["detail"] != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "detail must be a string"})
+		return
+	}
+	detail = strings.TrimSpace(detail)
+	if utf8.RuneCountInString(detail) > maxReportDetailLength {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "detail is too long"})
+		return
+	}
+	if category == "other" && detail == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "举报原因为 other 时必须填写说明"})
+		return
+	}
+
+	// 只能举报自己看得到的他人内容
+	authorID, ok := loadReportTarget(c, targetType, *targetID)
+	if !ok {
+		return
+	}
+	if authorID != nil && *authorID == userId {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "不能举报自己的内容"})
+		return
+	}
+
+	report := models.Report{
+		TargetType: targetType,
+		TargetID:   *targetID,
+		ReporterID: userId,
+		Category:   category,
+		Detail:     detail,
+		Status:     models.ReportStatusOpen,
+	}
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		// 唯一索引保证同一用户对同一内容只有一条举报
+		result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&report)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return invalidInput("你已经举报过该内容")
+		}
+		return hideReportedContent(tx, targetType, *targetID)
+	})
+	if err != nil {
+		if isInputError(err) {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "举报失败"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, report)
+}
+
+// GetReports 获取举报审核队列（管理员）
+// 查询参数: status - open（默认）、dismissed 或 resolved, target_type - 可选，post 或 comment,
+// category - 可选，原因分类, page/page_size - 分页
+// 参数: c - Gin上下文
+func GetReports(c *gin.Context) {
+	status := c.DefaultQuery("status", models.ReportStatusOpen)
+	if status != models.ReportStatusOpen && status != models.ReportStatusDismissed && status != models.ReportStatusResolved {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "status must be open, dismissed or resolved"})
+		return
+	}
+	page, pageSize := parsePagination(c)
+
+	query := database.DB.Model(&models.Report{}).Where("status = ?", status)
+	if targetType := c.Query("target_type"); targetType != "" {
+		if targetType != models.ReportTargetPost && targetType != models.ReportTargetComment {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "target_type must be post or comment"})
+			return
+		}
+		query = query.Where("target_type = ?", targetType)
+	}
+	if category := c.Query("category"); category != "" {
+		query = query.Where("category = ?", category)
+	}
+
+	var total int64
+	if err := query.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "获取举报失败"})
+		return
+	}
+
+	// 待处理的举报先进先出，已处理的举报按处理时间倒序
+	order := "created_at, id"
+	if status != models.ReportStatusOpen {
+		order = "resolved_at DESC, id DESC"
+	}
+	var reports []models.Report
+	if err := query.Order(order).Offset((page - 1) * pageSize).Limit(pageSize).Find(&reports).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "获取举报失败"})
+		return
+	}
+
+	items, err := buildReportItems(reports)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "获取举报失败"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"total":     total,
+		"page":      page,
+		"page_size": pageSize,
+		"items":     items,
+	})
+}
+
+// ResolveReport 处理举报（管理员），同一内容的全部待处理举报一并处理
+// 请求体: action - dismiss（驳回并恢复被隐藏的内容）、remove（删除内容）或 suspend（删除内容并停用作者账号）
+// 参数: c - Gin上下文
+func ResolveReport(c *gin.Context) {
+	userId, ok := currentUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的举报ID"})
+		return
+	}
+
+	var data map[string]interface{}
+	if err := c.ShouldBindJSON(&data); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	action, _ := data["action"].(string)
+	status := models.ReportStatusResolved
+	switch action {
+	case models.ReportActionDismiss:
+		status = models.ReportStatusDismissed
+	case models.ReportActionRemove, models.ReportActionSuspend:
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "action must be dismiss, remove or suspend"})
+		return
+	}
+
+	var report models.Report
+	if err := database.DB.First(&report, id).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "举报不存在"})
+		return
+	}
+	if report.Status != models.ReportStatusOpen {
+		c.JSON(http.StatusConflict, gin.H{"error": "该举报已处理"})
+		return
+	}
+
+	var resolved int64
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := applyReportAction(tx, report.TargetType, report.TargetID, action); err != nil {
+			return err
+		}
+		now := time.Now()
+		result := tx.Model(&models.Report{}).
+			Where("target_type = ? AND target_id = ? AND status = ?", report.TargetType, report.TargetID, models.ReportStatusOpen).
+			Updates(map[string]interface{}{"status": status, "action": action, "resolver_id": userId, "resolved_at": now, "updated_at": now})
+		resolved = result.RowsAffected
+		return result.Error
+	})
+	if err != nil {
+		if isInputError(err) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "处理举报失败"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"action": action, "status": status, "resolved": resolved})
+}
+
+// ReinstateUser 恢复被停用的账号（管理员）
+// 参数: c - Gin上下文
+func ReinstateUser(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的用户ID"})
+		return
+	}
+
+	result := database.DB.Model(&models.User{}).Where("id = ?", id).UpdateColumn("suspended_at", nil)
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "恢复账号失败"})
+		return
+	}
+	if result.RowsAffected == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "用户不存在"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "账号已恢复"})
+}
+
+// loadReportTarget 加载当前用户可以看到的被举报内容，失败时直接写入错误响应
+// 参数: c - Gin上下文, targetType - 对象类型, targetID - 对象ID
+// 返回值: 内容作者ID（游客评论为空）, 是否成功
+func loadReportTarget(c *gin.Context, targetType string, targetID uint) (*uint, bool) {
+	postID := targetID
+	var authorID *uint
+	if targetType == models.ReportTargetComment {
+		var comment models.Comment
+		if err := database.DB.Select("id", "post_id", "user_id", "status", "hidden_at").First(&comment, targetID).Error; err != nil ||
+			comment.Status != models.CommentStatusApproved || comment.HiddenAt != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "评论不存在"})
+			return nil, false
+		}
+		postID, authorID = comment.PostID, comment.UserID
+	}
+
+	var post models.Post
+	if err := database.DB.Select("id", "user_id", "status", "visibility", "password_hash", "hidden_at").First(&post, postID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "文章不存在"})
+		return nil, false
+	}
+	if !checkPostAccess(c, &post) {
+		return nil, false
+	}
+	if targetType == models.ReportTargetPost {
+		authorID = &post.UserID
+	}
+	return authorID, true
+}
+
+// hideReportedContent 待处理的举报数达到阈值时隐藏内容
+// 参数: tx - 数据库事务, targetType - 对象类型, targetID - 对象ID
+// 返回值: 错误信息
+func hideReportedContent(tx *gorm.DB, targetType string, targetID uint) error {
+	threshold := config.LoadConfig().ReportHideCount
+	if threshold <= 0 {
+		return nil
+	}
+	var open int64
+	if err := tx.Model(&models.Report{}).
+		Where("target_type = ? AND target_id = ? AND status = ?", targetType, targetID, models.ReportStatusOpen).
+		Count(&open).Error; err != nil {
+		return err
+	}
+	if open < int64(threshold) {
+		return nil
+	}
+	return hideReportTarget(tx, targetType, targetID)
+}
+
+// applyReportAction 对被举报内容执行处理
+// 驳回时恢复被隐藏的内容；删除文章时移入回收站（恢复后仍保持隐藏），删除评论时软删除；
+// 停用作者时同时删除内容，游客评论和管理员的内容不能停用作者
+// 参数: tx - 数据库事务, targetType - 对象类型, targetID - 对象ID, action - 处理方式
+// 返回值: 错误信息
+func applyReportAction(tx *gorm.DB, targetType string, targetID uint, action string) error {
+	if action == models.ReportActionDismiss {
+		// 同时更新修改时间，使 Last-Modified 随可见性变化
+		return reportTargetQuery(tx, targetType, targetID).Where("hidden_at IS NOT NULL").
+			UpdateColumns(map[string]interface{}{"hidden_at": nil, "updated_at": time.Now()}).Error
+	}
+
+	if action == models.ReportActionSuspend {
+		var authorID *uint
+		if targetType == models.ReportTargetPost {
+			var post models.Post
+			if err := tx.Unscoped().Select("id", "user_id").First(&post, targetID).Error; err != nil {
+				return err
+			}
+			authorID = &post.UserID
+		} else {
+			var comment models.Comment
+			if err := tx.Unscoped().Select("id", "user_id").First(&comment, targetID).Error; err != nil {
+				return err
+			}
+			authorID = comment.UserID
+		}
+		if authorID == nil {
+			return invalidInput("游客评论没有可停用的账号")
+		}
+		var author models.User
+		if err := tx.Select("id", "role").First(&author, *authorID).Error; err != nil {
+			return err
+		}
+		if author.Role == models.RoleAdmin {
+			return invalidInput("不能停用管理员账号")
+		}
+		if err := tx.Model(&author).Where("suspended_at IS NULL").UpdateColumn("suspended_at", time.Now()).Error; err != nil {
+			return err
+		}
+	}
+
+	if targetType == models.ReportTargetPost {
+		// 先隐藏再移入回收站，作者从回收站恢复后文章仍保持隐藏
+		if err := hideReportTarget(tx, targetType, targetID); err != nil {
+			return err
+		}
+		_, err := trashPost(tx, targetID)
+		return err
+	}
+	return tx.Delete(&models.Comment{}, targetID).Error
+}
+
+// hideReportTarget 隐藏被举报内容，已隐藏的内容保持原来的隐藏时间
+// 同时更新修改时间，使 Last-Modified 随可见性变化
+func hideReportTarget(tx *gorm.DB, targetType string, targetID uint) error {
+	now := time.Now()
+	return reportTargetQuery(tx, targetType, targetID).Where("hidden_at IS NULL").
+		UpdateColumns(map[string]interface{}{"hidden_at": now, "updated_at": now}).Error
+}
+
+// reportTargetQuery 构造被举报内容的查询，包括已删除的内容
+func reportTargetQuery(tx *gorm.DB, targetType string, targetID uint) *gorm.DB {
+	if targetType == models.ReportTargetPost {
+		return tx.Unscoped().Model(&models.Post{}).Where("id = ?", targetID)
+	}
+	return tx.Unscoped().Model(&models.Comment{}).Where("id = ?", targetID)
+}
+
+// buildReportItems 为举报批量加载被举报内容的摘要和待处理的举报数
+// 参数: reports - 举报列表
+// 返回值: 审核队列项, 错误信息
+func buildReportItems(reports []models.Report) ([]reportItem, error) {
+	var postIDs, commentIDs []uint
+	for _, report := range reports {
+		if report.TargetType == models.ReportTargetPost {
+			postIDs = append(postIDs, report.TargetID)
+		} else {
+			commentIDs = append(commentIDs, report.TargetID)
+		}
+	}
+
+	targets := make(map[string]*reportTarget, len(reports))
+	if len(postIDs) > 0 {
+		var posts []models.Post
+		if err := database.DB.Unscoped().Select("id", "user_id", "title", "content", "hidden_at", "deleted_at").
+			Where("id IN ?", postIDs).Find(&posts).Error; err != nil {
+			return nil, err
+		}
+		for _, post := range posts {
+			authorID := post.UserID
+			targets[reportTargetKey(models.ReportTargetPost, post.ID)] = &reportTarget{
+				AuthorID: &authorID, PostID: post.ID, Title: post.Title, Content: post.Content,
+				Hidden: post.HiddenAt != nil, Removed: post.DeletedAt.Valid,
+			}
+		}
+	}
+	if len(commentIDs) > 0 {
+		var comments []models.Comment
+		if err := database.DB.Unscoped().Select("id", "post_id", "user_id", "content", "hidden_at", "deleted_at").
+			Where("id IN ?", commentIDs).Find(&comments).Error; err != nil {
+			return nil, err
+		}
+		for _, comment := range comments {
+			targets[reportTargetKey(models.ReportTargetComment, comment.ID)] = &reportTarget{
+				AuthorID: comment.UserID, PostID: comment.PostID, Content: comment.Content,
+				Hidden: comment.HiddenAt != nil, Removed: comment.DeletedAt.Valid,
+			}
+		}
+	}
+
+	// 各内容待处理的举报数
+	type openCount struct {
+		TargetType string
+		TargetID   uint
+		Count      int64
+	}
+	var counts []openCount
+	if len(reports) > 0 {
+		err := database.DB.Model(&models.Report{}).Select("target_type, target_id, COUNT(*) AS count").
+			Where("status = ? AND ((target_type = ? AND target_id IN ?) OR (target_type = ? AND target_id IN ?))",
+				models.ReportStatusOpen, models.ReportTargetPost, postIDs, models.ReportTargetComment, commentIDs).
+			Group("target_type, target_id").Scan(&counts).Error
+		if err != nil {
+			return nil, err
+		}
+	}
+	open := make(map[string]int64, len(counts))
+	for _, count := range counts {
+		open[reportTargetKey(count.TargetType, count.TargetID)] = count.Count
+	}
+
+	items := make([]reportItem, len(reports))
+	for i, report := range reports {
+		key := reportTargetKey(report.TargetType, report.TargetID)
+		items[i] = reportItem{Report: report, Target: targets[key], OpenReports: open[key]}
+	}
+	return items, nil
+}
+
+// reportTargetKey 被举报内容的唯一标识
+func reportTargetKey(targetType string, targetID uint) string {
+	return targetType + ":" + strconv.FormatUint(uint64(targetID), 10)
+}
@@ -0,0 +1,250 @@
+package controllers
+
+import (
+	"blog-system/database"
+	"blog-system/models"
+	"blog-system/utils"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// snippetWidth 搜索结果摘要长度（字符数）
+const snippetWidth = 120
+
+// searchRow 搜索查询的原始结果
+type searchRow struct {
+	ID        uint
+	PostID    uint
+	Title     string
+	Content   string
+	UserID    uint
+	CreatedAt time.Time
+	Score     float64
+}
+
+// searchHit 单条搜索结果
+type searchHit struct {
+	ID        uint      `json:"id"`
+	PostID    uint      `json:"post_id,omitempty"`
+	Title     string    `json:"title,omitempty"`
+	Highlight string    `json:"title_highlight,omitempty"`
+	Snippet   string    `json:"snippet"`
+	UserID    uint      `json:"user_id"`
+	CreatedAt time.Time `json:"created_at"`
+	Score     float64   `json:"score"`
+}
+
+// searchResult 分页后的搜索结果
+type searchResult struct {
+	Total int64       `json:"total"`
+	Items []searchHit `json:"items"`
+}
+
+// Search 全文搜索文章和评论
+// 查询参数: q - 关键词, type - posts|comments|all, page/page_size - 分页,
+// 以及与文章列表相同的 tags/match/category_id/include_children/author_id 过滤条件（评论按所属文章过滤）
+// 参数: c - Gin上下文
+func Search(c *gin.Context) {
+	terms := utils.SearchTerms(c.Query("q"))
+	if len(terms) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "q is required"})
+		return
+	}
+
+	searchType := c.DefaultQuery("type", "posts")
+	if searchType != "posts" && searchType != "comments" && searchType != "all" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "type must be posts, comments or all"})
+		return
+	}
+	page, pageSize := parsePagination(c)
+
+	response := gin.H{"page": page, "page_size": pageSize, "mode": database.SearchMode}
+	if searchType != "comments" {
+		result, err := searchPosts(c, terms, page, pageSize)
+		if err != nil {
+			respondSearchError(c, err)
+			return
+		}
+		response["posts"] = result
+	}
+	if searchType != "posts" {
+		result, err := searchComments(c, terms, page, pageSize)
+		if err != nil {
+			respondSearchError(c, err)
+			return
+		}
+		response["comments"] = result
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// respondSearchError 将搜索错误映射为HTTP响应
+func respondSearchError(c *gin.Context, err error) {
+	if isInputError(err) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusInternalServerError, gin.H{"error": "搜索失败"})
+}
+
+// searchPosts 搜索文章
+func searchPosts(c *gin.Context, terms []string, page, pageSize int) (*searchResult, error) {
+	query, score := matchQuery(database.DB.Model(&models.Post{}).Scopes(searchablePosts), "posts", []string{"title", "content"}, terms)
+	query, err := applyPostFilters(c, query)
+	if err != nil {
+		return nil, err
+	}
+
+	var total int64
+	if err := query.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+		return nil, err
+	}
+
+	var rows []searchRow
+	err = query.Select("posts.id, posts.title, posts.content, posts.user_id, posts.created_at, "+score.expr+" AS score", score.args...).
+		Order("score DESC, posts.id DESC").
+		Offset((page - 1) * pageSize).Limit(pageSize).
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	result := &searchResult{Total: total, Items: make([]searchHit, 0, len(rows))}
+	for _, row := range rows {
+		result.Items = append(result.Items, searchHit{
+			ID:        row.ID,
+			Title:     row.Title,
+			Highlight: utils.Highlight(row.Title, terms),
+			Snippet:   utils.Snippet(row.Content, terms, snippetWidth),
+			UserID:    row.UserID,
+			CreatedAt: row.CreatedAt,
+			Score:     row.Score,
+		})
+	}
+	return result, nil
+}
+
+// searchComments 搜索评论，只包含已发布的公开文章下已通过审核的评论
+func searchComments(c *gin.Context, terms []string, page, pageSize int) (*searchResult, error) {
+	base := database.DB.Model(&models.Comment{}).
+		Joins("JOIN posts ON posts.id = comments.post_id AND posts.deleted_at IS NULL").
+		Where("comments.status = ? AND comments.hidden_at IS NULL", models.CommentStatusApproved).
+		Scopes(searchablePosts)
+	query, score := matchQuery(base, "comments", []string{"content"}, terms)
+	query, err := applyPostFilters(c, query)
+	if err != nil {
+		return nil, err
+	}
+
+	var total int64
+	if err := query.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+		return nil, err
+	}
+
+	var rows []searchRow
+	err = query.Select("comments.id, comments.post_id, posts.title, comments.content, comments.user_id, comments.created_at, "+score.expr+" AS score", score.args...).
+		Order("score DESC, comments.id DESC").
+		Offset((page - 1) * pageSize).Limit(pageSize).
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	result := &searchResult{Total: total, Items: make([]searchHit, 0, len(rows))}
+	for _, row := range rows {
+		result.Items = append(result.Items, searchHit{
+			ID:        row.ID,
+			PostID:    row.PostID,
+			Title:     row.Title,
+			Snippet:   utils.Snippet(row.Content, terms, snippetWidth),
+			UserID:    row.UserID,
+			CreatedAt: row.CreatedAt,
+			Score:     row.Score,
+		})
+	}
+	return result, nil
+}
+
+// scoreExpr 相关度表达式及其参数
+type scoreExpr struct {
+	expr string
+	args []interface{}
+}
+
+// matchQuery 根据当前检索模式为查询添加全文匹配条件
+// 参数: query - 基础查询, table - 表名, columns - 检索列, terms - 检索词
+// 返回值: 带匹配条件的查询, 相关度表达式（越大越相关）
+func matchQuery(query *gorm.DB, table string, columns []string, terms []string) (*gorm.DB, scoreExpr) {
+	switch database.SearchMode {
+	case database.SearchModeFullText:
+		// 布尔模式下每个词都必须出现，引号内按短语匹配 ngram
+		parts := make([]string, len(terms))
+		for i, term := range terms {
+			parts[i] = `+"` + strings.ReplaceAll(term, `"`, "") + `"`
+		}
+		against := strings.Join(parts, " ")
+		match := "MATCH(" + qualify(table, columns) + ") AGAINST (? IN BOOLEAN MODE)"
+		return query.Where(match, against), scoreExpr{expr: match, args: []interface{}{against}}
+
+	case database.SearchModeFTS5:
+		// 检索词与索引使用相同的切分方式，再以短语匹配相邻的字符
+		parts := make([]string, len(terms))
+		for i, term := range terms {
+			parts[i] = `"` + strings.ReplaceAll(strings.TrimSpace(utils.SegmentSearchText(term)), `"`, `""`) + `"`
+		}
+		fts := table + "_fts"
+		// bm25 越小越相关，标题权重高于正文
+		weights := make([]string, len(columns))
+		for i := range weights {
+			weights[i] = "1.0"
+		}
+		if len(weights) > 1 {
+			weights[0] = "10.0"
+		}
+		query = query.Joins("JOIN "+fts+" ON "+fts+".rowid = "+table+".id").
+			Where(fts+" MATCH ?", strings.Join(parts, " AND "))
+		return query, scoreExpr{expr: "-bm25(" + fts + ", " + strings.Join(weights, ", ") + ")"}
+
+	default:
+		// 相关度为各检索词在各列中出现的次数之和，与 bm25 一致按标题 10 倍、正文 1 倍加权
+		var scores []string
+		var scoreArgs []interface{}
+		for _, term := range terms {
+			pattern := "%" + escapeLike(term) + "%"
+			lower := strings.ToLower(term)
+			conditions := make([]string, len(columns))
+			args := make([]interface{}, len(columns))
+			for i, column := range columns {
+				qualified := table + "." + column
+				conditions[i] = qualified + " LIKE ? ESCAPE '!'"
+				args[i] = pattern
+				weight := "1.0"
+				if i == 0 && len(columns) > 1 {
+					weight = "10.0"
+				}
+				scores = append(scores, weight+" * (LENGTH("+qualified+") - LENGTH(REPLACE(LOWER("+qualified+"), ?, ''))) / LENGTH(?)")
+				scoreArgs = append(scoreArgs, lower, lower)
+			}
+			query = query.Where("("+strings.Join(conditions, " OR ")+")", args...)
+		}
+		return query, scoreExpr{expr: "(" + strings.Join(scores, " + ") + ")", args: scoreArgs}
+	}
+}
+
+// qualify 为列名加上表名前缀
+func qualify(table string, columns []string) string {
+	qualified := make([]string, len(columns))
+	for i, column := range columns {
+		qualified[i] = table + "." + column
+	}
+	return strings.Join(qualified, ", ")
+}
+
+// escapeLike 转义 LIKE 模式中的通配符
+func escapeLike(s string) string {
+	return strings.NewReplacer(`!`, `!!`, `%`, `!%`, `_`, `!_`).Replace(s)
+}
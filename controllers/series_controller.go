@@ -0,0 +1,473 @@
+package controllers
+
+import (
+	"blog-system/database"
+	"blog-system/models"
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxSeriesParts 每个系列最多包含的文章数
+const maxSeriesParts = 100
+
+// errSeriesConflict 文章已属于其他系列
+var errSeriesConflict = errors.New("文章已属于其他系列")
+
+// seriesPart 系列中的一篇文章
+type seriesPart struct {
+	SortOrder   int        `json:"-"`        // 存储的排序值，隐藏文章后可能不连续
+	Position    int        `json:"position"` // 在可见文章中的序号，从1开始
+	ID          uint       `json:"id"`
+	Title       string     `json:"title"`
+	Status      string     `json:"status"`
+	PublishedAt *time.Time `json:"published_at"`
+}
+
+// seriesSummary 系列列表项
+type seriesSummary struct {
+	models.Series
+	PostCount int64 `json:"post_count"` // 系列中公开列出的文章数
+}
+
+// GetSeriesList 获取系列列表
+// 查询参数: author_id - 可选，只返回该用户创建的系列, page/page_size - 分页
+// 参数: c - Gin上下文
+func GetSeriesList(c *gin.Context) {
+	page, pageSize := parsePagination(c)
+
+	query := database.DB.Model(&models.Series{})
+	if raw := c.Query("author_id"); raw != "" {
+		authorID, err := strconv.ParseUint(raw, 10, 32)
+		if err != nil || authorID == 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "无效的作者ID"})
+			return
+		}
+		query = query.Where("user_id = ?", authorID)
+	}
+
+	var total int64
+	if err := query.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "获取系列列表失败"})
+		return
+	}
+
+	var list []models.Series
+	if err := query.Order("updated_at DESC").Offset((page - 1) * pageSize).Limit(pageSize).Find(&list).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "获取系列列表失败"})
+		return
+	}
+
+	// 统计各系列中公开列出的文章数
+	ids := make([]uint, len(list))
+	for i, series := range list {
+		ids[i] = series.ID
+	}
+	var counts []struct {
+		SeriesID uint
+		Count    int64
+	}
+	err := database.DB.Model(&models.SeriesPost{}).
+		Select("series_posts.series_id, COUNT(*) AS count").
+		Joins("JOIN posts ON posts.id = series_posts.post_id AND posts.deleted_at IS NULL").
+		Scopes(listedPosts).
+		Where("series_posts.series_id IN ?", ids).
+		Group("series_posts.series_id").
+		Scan(&counts).Error
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "获取系列列表失败"})
+		return
+	}
+	countByID := make(map[uint]int64, len(counts))
+	for _, row := range counts {
+		countByID[row.SeriesID] = row.Count
+	}
+
+	items := make([]seriesSummary, len(list))
+	for i, series := range list {
+		items[i] = seriesSummary{Series: series, PostCount: countByID[series.ID]}
+	}
+
+	c.JSON(http.StatusOK, gin.H{"total": total, "page": page, "page_size": pageSize, "items": items})
+}
+
+// GetSeries 获取系列及按顺序排列的文章
+// 草稿等未公开列出的文章只对系列创建者可见
+// 参数: c - Gin上下文
+func GetSeries(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的系列ID"})
+		return
+	}
+
+	var series models.Series
+	if err := database.DB.First(&series, id).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "系列不存在"})
+		return
+	}
+
+	userId, _ := currentUserID(c)
+	parts, err := loadSeriesParts(series.ID, userId != 0 && userId == series.UserID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "获取系列失败"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"series": series, "posts": parts})
+}
+
+// CreateSeries 创建系列
+// 请求体: title - 标题, description - 可选简介, post_ids - 可选，按顺序排列的文章ID
+// 参数: c - Gin上下文
+func CreateSeries(c *gin.Context) {
+	userId, ok := currentUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+
+	var data map[string]interface{}
+	if err := c.ShouldBindJSON(&data); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	series := models.Series{UserID: userId}
+	title, ok := data["title"].(string)
+	series.Title = strings.TrimSpace(title)
+	if !ok || series.Title == "" || len(series.Title) > 200 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "title must be a non-empty string of at most 200 characters"})
+		return
+	}
+	if raw, exists := data["description"]; exists && raw != nil {
+		if series.Description, ok = raw.(string); !ok {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "description must be a string"})
+			return
+		}
+	}
+	postIDs, err := parseIDList(data["post_ids"])
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "post_ids " + err.Error()})
+		return
+	}
+
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&series).Error; err != nil {
+			return err
+		}
+		return setSeriesPosts(tx, series.ID, userId, postIDs)
+	})
+	if err != nil {
+		respondSeriesError(c, err)
+		return
+	}
+
+	parts, err := loadSeriesParts(series.ID, true)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "获取系列失败"})
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"series": series, "posts": parts})
+}
+
+// UpdateSeries 修改系列标题和简介（仅创建者）
+// 参数: c - Gin上下文
+func UpdateSeries(c *gin.Context) {
+	series, ok := loadOwnedSeries(c)
+	if !ok {
+		return
+	}
+
+	var data map[string]interface{}
+	if err := c.ShouldBindJSON(&data); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	updateData := make(map[string]interface{})
+	if raw, exists := data["title"]; exists {
+		title, ok := raw.(string)
+		title = strings.TrimSpace(title)
+		if !ok || title == "" || len(title) > 200 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "title must be a non-empty string of at most 200 characters"})
+			return
+		}
+		updateData["title"] = title
+	}
+	if raw, exists := data["description"]; exists {
+		description, ok := raw.(string)
+		if !ok && raw != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "description must be a string"})
+			return
+		}
+		updateData["description"] = description
+	}
+	if len(updateData) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "没有提供有效的更新字段"})
+		return
+	}
+
+	if err := database.DB.Model(series).Updates(updateData).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "更新系列失败"})
+		return
+	}
+	c.JSON(http.StatusOK, series)
+}
+
+// ReorderSeries 设置系列包含的文章及其顺序（仅创建者）
+// 请求体: post_ids - 按顺序排列的文章ID，未列出的文章会移出系列
+// 参数: c - Gin上下文
+func ReorderSeries(c *gin.Context) {
+	series, ok := loadOwnedSeries(c)
+	if !ok {
+		return
+	}
+
+	var data map[string]interface{}
+	if err := c.ShouldBindJSON(&data); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if _, exists := data["post_ids"]; !exists {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "post_ids is required"})
+		return
+	}
+	postIDs, err := parseIDList(data["post_ids"])
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "post_ids " + err.Error()})
+		return
+	}
+
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := setSeriesPosts(tx, series.ID, series.UserID, postIDs); err != nil {
+			return err
+		}
+		// 顺序变化会影响文章中的上一篇/下一篇，刷新系列更新时间
+		return tx.Model(series).Update("updated_at", time.Now()).Error
+	})
+	if err != nil {
+		respondSeriesError(c, err)
+		return
+	}
+
+	parts, err := loadSeriesParts(series.ID, true)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "获取系列失败"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"series": series, "posts": parts})
+}
+
+// DeleteSeries 删除系列，系列中的文章不受影响（仅创建者）
+// 参数: c - Gin上下文
+func DeleteSeries(c *gin.Context) {
+	series, ok := loadOwnedSeries(c)
+	if !ok {
+		return
+	}
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("series_id = ?", series.ID).Delete(&models.SeriesPost{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(series).Error
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "删除系列失败"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "系列删除成功"})
+}
+
+// loadOwnedSeries 加载当前用户创建的系列，失败时直接写入错误响应
+// 参数: c - Gin上下文
+// 返回值: 系列, 是否成功
+func loadOwnedSeries(c *gin.Context) (*models.Series, bool) {
+	userId, ok := currentUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return nil, false
+	}
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的系列ID"})
+		return nil, false
+	}
+
+	var series models.Series
+	if err := database.DB.First(&series, id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "系列不存在"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		}
+		return nil, false
+	}
+	if series.UserID != userId {
+		c.JSON(http.StatusForbidden, gin.H{"error": "You are not the owner of this series"})
+		return nil, false
+	}
+	return &series, true
+}
+
+// setSeriesPosts 替换系列中的文章及顺序
+// 用户需要对每篇文章具有编辑权限，且文章不能属于其他系列
+// 参数: tx - 数据库事务, seriesID - 系列ID, userID - 操作用户ID, postIDs - 按顺序排列的文章ID
+func setSeriesPosts(tx *gorm.DB, seriesID, userID uint, postIDs []uint) error {
+	if len(postIDs) > maxSeriesParts {
+		return invalidInput("每个系列最多包含 %d 篇文章", maxSeriesParts)
+	}
+
+	if len(postIDs) > 0 {
+		var posts []models.Post
+		if err := tx.Select("id", "user_id").Where("id IN ?", postIDs).Find(&posts).Error; err != nil {
+			return err
+		}
+		if len(posts) != len(postIDs) {
+			return invalidInput("文章不存在")
+		}
+		for i := range posts {
+			role, err := posts[i].RoleOf(tx, userID)
+			if err != nil {
+				return err
+			}
+			if !models.RoleAtLeast(role, models.CollaboratorCoAuthor) {
+				return invalidInput("没有文章 %d 的编辑权限", posts[i].ID)
+			}
+		}
+
+		var count int64
+		if err := tx.Model(&models.SeriesPost{}).Where("post_id IN ? AND series_id <> ?", postIDs, seriesID).Count(&count).Error; err != nil {
+			return err
+		}
+		if count > 0 {
+			return errSeriesConflict
+		}
+	}
+
+	if err := tx.Where("series_id = ?", seriesID).Delete(&models.SeriesPost{}).Error; err != nil {
+		return err
+	}
+	if len(postIDs) == 0 {
+		return nil
+	}
+	parts := make([]models.SeriesPost, len(postIDs))
+	for i, postID := range postIDs {
+		parts[i] = models.SeriesPost{SeriesID: seriesID, PostID: postID, Position: i + 1}
+	}
+	return tx.Create(&parts).Error
+}
+
+// loadSeriesParts 按顺序查询系列中的文章，已删除的文章不包含在内
+// 参数: seriesID - 系列ID, includeDrafts - 是否包含草稿等未公开列出的文章
+// 返回值: 文章列表, 错误信息
+func loadSeriesParts(seriesID uint, includeDrafts bool) ([]seriesPart, error) {
+	query := database.DB.Model(&models.SeriesPost{}).
+		Select("series_posts.position AS sort_order, posts.id, posts.title, posts.status, posts.published_at").
+		Joins("JOIN posts ON posts.id = series_posts.post_id AND posts.deleted_at IS NULL").
+		Where("series_posts.series_id = ?", seriesID)
+	if !includeDrafts {
+		query = query.Scopes(listedPosts)
+	}
+
+	parts := []seriesPart{}
+	if err := query.Order("series_posts.position ASC").Scan(&parts).Error; err != nil {
+		return nil, err
+	}
+	for i := range parts {
+		parts[i].Position = i + 1
+	}
+	return parts, nil
+}
+
+// loadSeriesNav 生成文章所属系列的导航信息，上一篇/下一篇只考虑公开列出的文章
+// 参数: post - 文章
+// 返回值: 导航信息（不属于任何系列时为 nil）, 系列的更新时间, 错误信息
+func loadSeriesNav(post *models.Post) (*models.SeriesNav, time.Time, error) {
+	var link models.SeriesPost
+	if err := database.DB.Where("post_id = ?", post.ID).First(&link).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, time.Time{}, nil
+		}
+		return nil, time.Time{}, err
+	}
+	var series models.Series
+	if err := database.DB.First(&series, link.SeriesID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, time.Time{}, nil
+		}
+		return nil, time.Time{}, err
+	}
+
+	parts, err := loadSeriesParts(series.ID, false)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+
+	nav := &models.SeriesNav{ID: series.ID, Title: series.Title, Position: 1, Total: len(parts)}
+	current := false
+	for i := range parts {
+		part := &parts[i]
+		switch {
+		case part.ID == post.ID:
+			current = true
+		case part.SortOrder < link.Position:
+			nav.Position++
+			nav.Prev = &models.SeriesLink{ID: part.ID, Title: part.Title, URL: fmt.Sprintf("/posts/%d", part.ID)}
+		case nav.Next == nil:
+			nav.Next = &models.SeriesLink{ID: part.ID, Title: part.Title, URL: fmt.Sprintf("/posts/%d", part.ID)}
+		}
+	}
+	// 查看草稿或未列出的文章时，文章本身也计入系列
+	if !current {
+		nav.Total++
+	}
+	return nav, series.UpdatedAt, nil
+}
+
+// parseIDList 解析JSON中的ID数组，拒绝重复的ID
+// 参数: raw - JSON 值，nil 表示空列表
+// 返回值: ID列表, 错误信息
+func parseIDList(raw interface{}) ([]uint, error) {
+	if raw == nil {
+		return nil, nil
+	}
+	items, ok := raw.([]interface{})
+	if !ok {
+		return nil, errors.New("must be an array of ids")
+	}
+
+	ids := make([]uint, 0, len(items))
+	seen := make(map[uint]bool, len(items))
+	for _, item := range items {
+		id, err := parseOptionalID(item)
+		if err != nil || id == nil {
+			return nil, errors.New("must be an array of positive integers")
+		}
+		if seen[*id] {
+			return nil, fmt.Errorf("contains duplicate id %d", *id)
+		}
+		seen[*id] = true
+		ids = append(ids, *id)
+	}
+	return ids, nil
+}
+
+// respondSeriesError 将系列操作的错误映射为HTTP响应
+func respondSeriesError(c *gin.Context, err error) {
+	switch {
+	case isInputError(err):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, errSeriesConflict):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "保存系列失败"})
+	}
+}
@@ -0,0 +1,127 @@
+package controllers
+
+import (
+	"blog-system/config"
+	"blog-system/database"
+	"blog-system/models"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// 统计时间范围
+const (
+	defaultStatsDays = 30
+	maxStatsDays     = 365
+)
+
+// dailyStat 单日统计
+type dailyStat struct {
+	Date      string `json:"date"`
+	Views     int64  `json:"views"`
+	Reactions int64  `json:"reactions"`
+	Comments  int64  `json:"comments"`
+}
+
+// GetPostStats 获取文章的浏览量、反应和评论统计（作者和合著者）
+// 浏览量定期批量写入，会有短暂延迟；日期按 UTC 划分
+// 查询参数: days - 统计最近多少天，默认 30，最多 365
+// 参数: c - Gin上下文
+func GetPostStats(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的文章ID"})
+		return
+	}
+	days, err := strconv.Atoi(c.DefaultQuery("days", strconv.Itoa(defaultStatsDays)))
+	if err != nil || days < 1 || days > maxStatsDays {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("days must be between 1 and %d", maxStatsDays)})
+		return
+	}
+
+	now := time.Now().UTC()
+	from := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC).AddDate(0, 0, -(days - 1))
+	series := make([]dailyStat, days)
+	index := make(map[string]*dailyStat, days)
+	for i := range series {
+		series[i].Date = from.AddDate(0, 0, i).Format("2006-01-02")
+		index[series[i].Date] = &series[i]
+	}
+
+	var views []models.PostViewStat
+	if err := database.DB.Where("post_id = ? AND day >= ?", id, from.Format("2006-01-02")).Find(&views).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "获取统计失败"})
+		return
+	}
+	for _, stat := range views {
+		if day, ok := index[stat.Day]; ok {
+			day.Views += stat.Views
+		}
+	}
+
+	// 按 UTC 日期在内存中分桶，避免依赖不同数据库的日期函数
+	var reactedAt, commentedAt []time.Time
+	if err := database.DB.Model(&models.Reaction{}).Where("post_id = ? AND created_at >= ?", id, from).
+		Pluck("created_at", &reactedAt).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "获取统计失败"})
+		return
+	}
+	for _, t := range reactedAt {
+		if day, ok := index[t.UTC().Format("2006-01-02")]; ok {
+			day.Reactions++
+		}
+	}
+	if err := database.DB.Model(&models.Comment{}).Where("post_id = ? AND status = ? AND created_at >= ?", id, models.CommentStatusApproved, from).
+		Pluck("created_at", &commentedAt).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "获取统计失败"})
+		return
+	}
+	for _, t := range commentedAt {
+		if day, ok := index[t.UTC().Format("2006-01-02")]; ok {
+			day.Comments++
+		}
+	}
+
+	// 全部时间的累计值
+	var totals struct {
+		Views     int64 `json:"views"`
+		Reactions int64 `json:"reactions"`
+		Comments  int64 `json:"comments"`
+	}
+	if err := database.DB.Model(&models.PostViewStat{}).Where("post_id = ?", id).
+		Select("COALESCE(SUM(views), 0)").Scan(&totals.Views).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "获取统计失败"})
+		return
+	}
+	if err := database.DB.Model(&models.Reaction{}).Where("post_id = ?", id).Count(&totals.Reactions).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "获取统计失败"})
+		return
+	}
+	if err := database.DB.Model(&models.Comment{}).Where("post_id = ? AND status = ?", id, models.CommentStatusApproved).Count(&totals.Comments).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "获取统计失败"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"post_id": id,
+		"from":    series[0].Date,
+		"to":      series[len(series)-1].Date,
+		"totals":  totals,
+		"daily":   series,
+	})
+}
+
+// visitorKey 生成用于浏览量去重的访客标识
+// 登录用户按用户ID区分，匿名访客按 IP 和 User-Agent 的哈希区分，不保存原始 IP
+// 参数: c - Gin上下文
+func visitorKey(c *gin.Context) string {
+	if userId, ok := currentUserID(c); ok {
+		return "u:" + strconv.FormatUint(uint64(userId), 10)
+	}
+	sum := sha256.Sum256([]byte(config.LoadConfig().JWTSecret + "|" + c.ClientIP() + "|" + c.Request.UserAgent()))
+	return "a:" + hex.EncodeToString(sum[:12])
+}
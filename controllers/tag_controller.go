@@ -0,0 +1,203 @@
+package controllers
+
+import (
+	"blog-system/database"
+	"blog-system/models"
+	"blog-system/utils"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// maxTagsPerPost 单篇文章最多可设置的标签数
+const maxTagsPerPost = 10
+
+// tagWithCount 标签及其关联文章数
+type tagWithCount struct {
+	ID        uint   `json:"id"`
+	Name      string `json:"name"`
+	PostCount int64  `json:"post_count"`
+}
+
+// GetTags 获取所有标签及各标签下的文章数
+// 参数: c - Gin上下文
+func GetTags(c *gin.Context) {
+	var tags []tagWithCount
+
+	// 只统计出现在公开列表中的文章
+	err := database.DB.Model(&models.Tag{}).
+		Select("tags.id, tags.name, COUNT(posts.id) AS post_count").
+		Joins("LEFT JOIN post_tags ON post_tags.tag_id = tags.id").
+		Joins("LEFT JOIN posts ON posts.id = post_tags.post_id AND posts.deleted_at IS NULL AND posts.status = ? AND posts.visibility IN ? AND posts.hidden_at IS NULL",
+			models.PostStatusPublished, []string{models.VisibilityPublic, models.VisibilityPassword}).
+		Group("tags.id, tags.name").
+		Order("post_count DESC, tags.name ASC").
+		Scan(&tags).Error
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "获取标签列表失败"})
+		return
+	}
+
+	c.JSON(http.StatusOK, tags)
+}
+
+// MergeTags 合并标签（管理员）
+// 将 sources 中的标签并入 target，来源标签随后被删除
+// 参数: c - Gin上下文
+func MergeTags(c *gin.Context) {
+	var data map[string]interface{}
+	if err := c.ShouldBindJSON(&data); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	targetRaw, ok := data["target"].(string)
+	target := utils.NormalizeTagName(targetRaw)
+	if !ok || target == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "target must be a non-empty string"})
+		return
+	}
+
+	sources, err := parseTagNames(data["sources"])
+	if err != nil || len(sources) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "sources must be a non-empty array of strings"})
+		return
+	}
+
+	var merged models.Tag
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		// 目标标签不存在时自动创建
+		targetTags, err := resolveTags(tx, []string{target})
+		if err != nil {
+			return err
+		}
+		merged = targetTags[0]
+
+		var sourceTags []models.Tag
+		if err := tx.Where("name IN ? AND id <> ?", sources, merged.ID).Find(&sourceTags).Error; err != nil {
+			return err
+		}
+
+		for _, source := range sourceTags {
+			if err := mergeTagInto(tx, source.ID, merged.ID); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "合并标签失败: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, merged)
+}
+
+// mergeTagInto 将来源标签的文章关联转移到目标标签并删除来源标签
+// 参数: tx - 事务, sourceID - 来源标签ID, targetID - 目标标签ID
+func mergeTagInto(tx *gorm.DB, sourceID, targetID uint) error {
+	var sourcePostIDs, targetPostIDs []uint
+	if err := tx.Table("post_tags").Where("tag_id = ?", sourceID).Pluck("post_id", &sourcePostIDs).Error; err != nil {
+		return err
+	}
+	if err := tx.Table("post_tags").Where("tag_id = ?", targetID).Pluck("post_id", &targetPostIDs).Error; err != nil {
+		return err
+	}
+
+	existing := make(map[uint]bool, len(targetPostIDs))
+	for _, id := range targetPostIDs {
+		existing[id] = true
+	}
+
+	// 只补充目标标签尚未关联的文章，避免联合主键冲突
+	for _, postID := range sourcePostIDs {
+		if existing[postID] {
+			continue
+		}
+		if err := tx.Exec("INSERT INTO post_tags (post_id, tag_id) VALUES (?, ?)", postID, targetID).Error; err != nil {
+			return err
+		}
+	}
+
+	if err := tx.Exec("DELETE FROM post_tags WHERE tag_id = ?", sourceID).Error; err != nil {
+		return err
+	}
+
+	// 来源标签被硬删除，由目标标签的修改时间体现文章标签的变化，供文章接口的 Last-Modified 使用
+	if err := tx.Model(&models.Tag{}).Where("id = ?", targetID).UpdateColumn("updated_at", time.Now()).Error; err != nil {
+		return err
+	}
+
+	// 硬删除来源标签，释放唯一的标签名
+	return tx.Unscoped().Delete(&models.Tag{}, sourceID).Error
+}
+
+// parseTagNames 从请求数据中解析标签名列表
+// 参数: raw - JSON 数组或逗号分隔的字符串
+// 返回值: 规范化并去重后的标签名, 错误信息
+func parseTagNames(raw interface{}) ([]string, error) {
+	var items []string
+	switch v := raw.(type) {
+	case nil:
+		return nil, nil
+	case string:
+		items = strings.Split(v, ",")
+	case []interface{}:
+		for _, item := range v {
+			name, ok := item.(string)
+			if !ok {
+				return nil, fmt.Errorf("tags must be strings")
+			}
+			items = append(items, name)
+		}
+	default:
+		return nil, fmt.Errorf("tags must be an array of strings")
+	}
+
+	seen := make(map[string]bool)
+	names := make([]string, 0, len(items))
+	for _, item := range items {
+		name := utils.NormalizeTagName(item)
+		if name == "" || seen[name] {
+			continue
+		}
+		if len(name) > 64 {
+			return nil, fmt.Errorf("tag %q is too long", name)
+		}
+		seen[name] = true
+		names = append(names, name)
+	}
+	return names, nil
+}
+
+// resolveTags 按名称查找标签，不存在的自动创建
+// 参数: tx - 数据库实例, names - 已规范化的标签名
+// 返回值: 标签列表, 错误信息
+func resolveTags(tx *gorm.DB, names []string) ([]models.Tag, error) {
+	tags := make([]models.Tag, 0, len(names))
+	for _, name := range names {
+		var tag models.Tag
+		if err := tx.Where(models.Tag{Name: name}).FirstOrCreate(&tag).Error; err != nil {
+			return nil, err
+		}
+		tags = append(tags, tag)
+	}
+	return tags, nil
+}
+
+// applyTagFilter 按标签过滤文章查询
+// 参数: query - 文章查询, names - 已规范化的标签名, matchAll - 是否要求包含全部标签
+// 返回值: 过滤后的查询
+func applyTagFilter(query *gorm.DB, names []string, matchAll bool) *gorm.DB {
+	sub := database.DB.Table("post_tags").
+		Select("post_tags.post_id").
+		Joins("JOIN tags ON tags.id = post_tags.tag_id AND tags.deleted_at IS NULL").
+		Where("tags.name IN ?", names)
+	if matchAll {
+		sub = sub.Group("post_tags.post_id").Having("COUNT(DISTINCT post_tags.tag_id) = ?", len(names))
+	}
+	return query.Where("posts.id IN (?)", sub)
+}
@@ -0,0 +1,63 @@
+package controllers
+
+import (
+	"sync"
+	"time"
+)
+
+// failureLimiter 按键统计固定时间窗口内的失败次数，达到上限后在窗口结束前拒绝尝试
+// 仅保存在当前进程内，窗口结束的记录定期清理
+type failureLimiter struct {
+	sync.Mutex
+	windows   map[string]*failureWindow
+	nextPrune time.Time // 下一次清理过期记录的时间
+}
+
+// failurePruneInterval 清理过期失败记录的最小间隔，避免每次失败都遍历全部记录
+const failurePruneInterval = time.Minute
+
+// failureWindow 一个键在当前窗口内的失败次数
+type failureWindow struct {
+	count   int
+	resetAt time.Time
+}
+
+// unlockFailures 文章密码验证失败的次数，按IP统计
+var unlockFailures = &failureLimiter{windows: make(map[string]*failureWindow)}
+
+// retryAfter 判断键是否已达到失败次数上限
+// 参数: key - 统计的键, limit - 窗口内允许的失败次数（为0时不限制）
+// 返回值: 需要等待的时长，未达到上限时为 0
+func (l *failureLimiter) retryAfter(key string, limit int) time.Duration {
+	if limit <= 0 {
+		return 0
+	}
+	l.Lock()
+	defer l.Unlock()
+	window, ok := l.windows[key]
+	if !ok || window.count < limit || !time.Now().Before(window.resetAt) {
+		return 0
+	}
+	return time.Until(window.resetAt)
+}
+
+// fail 记录一次失败，窗口从该键的第一次失败开始计算
+// 参数: key - 统计的键, window - 统计窗口
+func (l *failureLimiter) fail(key string, window time.Duration) {
+	l.Lock()
+	defer l.Unlock()
+	now := time.Now()
+	if !now.Before(l.nextPrune) {
+		for k, w := range l.windows {
+			if !now.Before(w.resetAt) {
+				delete(l.windows, k)
+			}
+		}
+		l.nextPrune = now.Add(failurePruneInterval)
+	}
+	if w, ok := l.windows[key]; ok && now.Before(w.resetAt) {
+		w.count++
+		return
+	}
+	l.windows[key] = &failureWindow{count: 1, resetAt: now.Add(window)}
+}
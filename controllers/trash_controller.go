@@ -0,0 +1,147 @@
+package controllers
+
+import (
+	"blog-system/config"
+	"blog-system/database"
+	"blog-system/models"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// trashedPost 回收站中的文章
+type trashedPost struct {
+	models.Post
+	PurgeAt *time.Time `json:"purge_at,omitempty"` // 预计被自动永久删除的时间
+}
+
+// GetTrash 获取当前用户回收站中的文章，包括作为所有者协作的文章
+// 参数: c - Gin上下文
+func GetTrash(c *gin.Context) {
+	userId, ok := currentUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+	page, pageSize := parsePagination(c)
+
+	owned := database.DB.Model(&models.PostCollaborator{}).Select("post_id").
+		Where("user_id = ? AND role = ?", userId, models.CollaboratorOwner)
+	query := database.DB.Unscoped().Model(&models.Post{}).
+		Where("(user_id = ? OR id IN (?)) AND deleted_at IS NOT NULL", userId, owned)
+
+	var total int64
+	if err := query.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "获取回收站失败"})
+		return
+	}
+
+	var posts []models.Post
+	if err := query.Preload("Tags").Order("deleted_at DESC").
+		Offset((page - 1) * pageSize).Limit(pageSize).Find(&posts).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "获取回收站失败"})
+		return
+	}
+
+	retention := config.LoadConfig().TrashRetention
+	items := make([]trashedPost, 0, len(posts))
+	for _, post := range posts {
+		item := trashedPost{Post: post}
+		if retention > 0 && post.DeletedAt.Valid {
+			purgeAt := post.DeletedAt.Time.Add(retention)
+			item.PurgeAt = &purgeAt
+		}
+		items = append(items, item)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"total":     total,
+		"page":      page,
+		"page_size": pageSize,
+		"items":     items,
+	})
+}
+
+// RestorePost 从回收站恢复文章及随其一起删除的评论
+// 参数: c - Gin上下文
+func RestorePost(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的文章ID"})
+		return
+	}
+
+	var post models.Post
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().First(&post, id).Error; err != nil {
+			return err
+		}
+		if !post.DeletedAt.Valid {
+			return invalidInput("文章不在回收站中")
+		}
+
+		// 单独删除的评论不随文章恢复
+		if err := tx.Unscoped().Model(&models.Comment{}).
+			Where("post_id = ? AND deleted_at = ?", post.ID, post.DeletedAt.Time).
+			UpdateColumn("deleted_at", nil).Error; err != nil {
+			return err
+		}
+		return tx.Unscoped().Model(&post).UpdateColumn("deleted_at", nil).Error
+	})
+	if err != nil {
+		switch {
+		case isInputError(err):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		case err == gorm.ErrRecordNotFound:
+			c.JSON(http.StatusNotFound, gin.H{"error": "文章不存在"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "恢复文章失败"})
+		}
+		return
+	}
+
+	var restored models.Post
+	if err := database.DB.Preload("Tags").Preload("Category").First(&restored, id).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "获取恢复后的文章失败"})
+		return
+	}
+
+	c.JSON(http.StatusOK, restored)
+}
+
+// PurgePost 永久删除回收站中的文章
+// 参数: c - Gin上下文
+func PurgePost(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的文章ID"})
+		return
+	}
+
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		var post models.Post
+		if err := tx.Unscoped().First(&post, id).Error; err != nil {
+			return err
+		}
+		// 只能永久删除已在回收站中的文章，防止误操作
+		if !post.DeletedAt.Valid {
+			return invalidInput("请先将文章移入回收站")
+		}
+		return database.PurgePosts(tx, []uint{post.ID})
+	})
+	if err != nil {
+		switch {
+		case isInputError(err):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		case err == gorm.ErrRecordNotFound:
+			c.JSON(http.StatusNotFound, gin.H{"error": "文章不存在"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "永久删除文章失败"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "文章已永久删除"})
+}
@@ -0,0 +1,222 @@
+package controllers
+
+import (
+	"blog-system/config"
+	"blog-system/database"
+	"blog-system/models"
+	"blog-system/utils"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"net/http"
+	"strconv"
+)
+
+// teaserWidth 受密码保护文章摘要的长度（字符数）
+const teaserWidth = 200
+
+// postAccessHeader 携带文章访问授权的请求头，也可以通过 access_token 查询参数传递
+const postAccessHeader = "X-Post-Access"
+
+// 当前用户对文章的访问级别
+const (
+	postAccessNone   = iota // 不可见
+	postAccessTeaser        // 只能查看摘要（受密码保护且未授权）
+	postAccessFull          // 可查看全部内容
+)
+
+// UnlockPost 使用密码换取受密码保护文章的短期访问授权
+// 请求体: password - 文章密码
+// 参数: c - Gin上下文
+func UnlockPost(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的文章ID"})
+		return
+	}
+
+	var data map[string]interface{}
+	if err := c.ShouldBindJSON(&data); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	password, ok := data["password"].(string)
+	if !ok || password == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "password is required"})
+		return
+	}
+
+	var post models.Post
+	if err := database.DB.Select("id", "status", "visibility", "password_hash").First(&post, id).Error; err != nil ||
+		post.Status != models.PostStatusPublished {
+		c.JSON(http.StatusNotFound, gin.H{"error": "文章不存在"})
+		return
+	}
+	if post.Visibility != models.VisibilityPassword {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "文章未设置访问密码"})
+		return
+	}
+
+	// 按IP限制密码错误次数，防止暴力猜测密码；不按文章限制，以免他人的错误尝试让知道密码的读者也无法访问
+	cfg := config.LoadConfig()
+	ip := c.ClientIP()
+	if wait := unlockFailures.retryAfter(ip, cfg.UnlockIPFailures); wait > 0 {
+		c.Header("Retry-After", strconv.Itoa(int(wait.Seconds())+1))
+		c.JSON(http.StatusTooManyRequests, gin.H{"error": "密码错误次数过多，请稍后再试"})
+		return
+	}
+	if !utils.CheckPassword(post.PasswordHash, password) {
+		unlockFailures.fail(ip, cfg.UnlockWindow)
+		c.JSON(http.StatusForbidden, gin.H{"error": "密码错误"})
+		return
+	}
+
+	token, expiresAt, err := utils.GeneratePostAccessToken(post.ID, post.PasswordHash, cfg.JWTSecret, cfg.PostAccessTTL)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "生成访问授权失败"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"access_token": token, "expires_at": expiresAt})
+}
+
+// postAccess 判断当前用户对文章的访问级别
+// 草稿、私密和因举报被隐藏的文章仅作者和协作者可见；受密码保护的文章需要访问授权，否则只能查看摘要
+// 参数: c - Gin上下文, post - 文章（需包含 status、visibility、password_hash、hidden_at 字段）
+// 返回值: 访问级别, 错误信息
+func postAccess(c *gin.Context, post *models.Post) (int, error) {
+	if post.Status == models.PostStatusPublished && post.HiddenAt == nil {
+		switch post.Visibility {
+		case models.VisibilityPublic, models.VisibilityUnlisted:
+			return postAccessFull, nil
+		case models.VisibilityPassword:
+			if hasPostGrant(c, post) {
+				return postAccessFull, nil
+			}
+		}
+	}
+
+	role, err := postRoleOf(c, post)
+	if err != nil {
+		return postAccessNone, err
+	}
+	if role != "" {
+		return postAccessFull, nil
+	}
+	if post.Status == models.PostStatusPublished && post.HiddenAt == nil && post.Visibility == models.VisibilityPassword {
+		return postAccessTeaser, nil
+	}
+	return postAccessNone, nil
+}
+
+// checkPostAccess 检查当前用户能否查看文章全文，失败时直接写入错误响应
+// 参数: c - Gin上下文, post - 文章
+// 返回值: 是否可以访问
+func checkPostAccess(c *gin.Context, post *models.Post) bool {
+	access, err := postAccess(c, post)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return false
+	}
+	switch access {
+	case postAccessNone:
+		c.JSON(http.StatusNotFound, gin.H{"error": "文章不存在"})
+		return false
+	case postAccessTeaser:
+		c.JSON(http.StatusForbidden, gin.H{"error": "该文章需要访问密码"})
+		return false
+	}
+	return true
+}
+
+// hasPostGrant 判断请求是否携带了该文章有效的访问授权
+func hasPostGrant(c *gin.Context, post *models.Post) bool {
+	token := c.GetHeader(postAccessHeader)
+	if token == "" {
+		token = c.Query("access_token")
+	}
+	if token == "" || post.PasswordHash == "" {
+		return false
+	}
+	return utils.VerifyPostAccessToken(token, post.ID, post.PasswordHash, config.LoadConfig().JWTSecret)
+}
+
+// isRestrictedPost 判断文章的响应是否因用户而异，不能被共享缓存
+func isRestrictedPost(post *models.Post) bool {
+	return post.Status != models.PostStatusPublished || post.Visibility == models.VisibilityPrivate ||
+		post.Visibility == models.VisibilityPassword
+}
+
+// lockPost 隐藏受密码保护文章的正文，只保留摘要
+// 参数: post - 文章
+func lockPost(post *models.Post) {
+	ensureRendered(post)
+	post.Locked = true
+	post.Teaser = utils.Teaser(post.ContentHTML, teaserWidth)
+	post.Content, post.ContentHTML, post.TOC = "", "", nil
+	post.Media = nil
+}
+
+// listedPosts 只查询出现在公开列表中的文章：已发布，且为公开或受密码保护
+// 参数: db - 包含 posts 表的查询
+func listedPosts(db *gorm.DB) *gorm.DB {
+	return db.Where("posts.status = ? AND posts.visibility IN ? AND posts.hidden_at IS NULL", models.PostStatusPublished,
+		[]string{models.VisibilityPublic, models.VisibilityPassword})
+}
+
+// searchablePosts 只查询可被搜索的文章：已发布且公开，避免受保护的正文出现在摘要中
+// 参数: db - 包含 posts 表的查询
+func searchablePosts(db *gorm.DB) *gorm.DB {
+	return db.Where("posts.status = ? AND posts.visibility = ? AND posts.hidden_at IS NULL", models.PostStatusPublished, models.VisibilityPublic)
+}
+
+// parseVisibility 解析请求中的可见性和访问密码
+// 参数: data - 请求体, current - 文章当前的可见性（创建时为空）
+// 返回值: 需要更新的字段（未提供时为空）, 错误信息
+func parseVisibility(data map[string]interface{}, current string) (map[string]interface{}, error) {
+	fields := make(map[string]interface{})
+	visibility := current
+	if raw, ok := data["visibility"]; ok {
+		value, ok := raw.(string)
+		switch value {
+		case models.VisibilityPublic, models.VisibilityUnlisted, models.VisibilityPrivate, models.VisibilityPassword:
+		default:
+			ok = false
+		}
+		if !ok {
+			return nil, invalidInput("visibility must be public, unlisted, private or password")
+		}
+		visibility = value
+		fields["visibility"] = value
+	}
+
+	raw := data["password"]
+	hasPassword := raw != nil
+	if visibility != models.VisibilityPassword {
+		if hasPassword {
+			return nil, invalidInput("password is only allowed when visibility is password")
+		}
+		// 取消密码保护时清除密码，重新设置时需要提供新密码
+		if current == models.VisibilityPassword && visibility != current {
+			fields["password_hash"] = ""
+		}
+		return fields, nil
+	}
+
+	if !hasPassword {
+		if current != models.VisibilityPassword {
+			return nil, invalidInput("password is required when visibility is password")
+		}
+		return fields, nil
+	}
+	password, ok := raw.(string)
+	if !ok || len(password) < 4 || len(password) > 72 {
+		return nil, invalidInput("password must be a string of 4 to 72 characters")
+	}
+	hash, err := utils.HashPassword(password)
+	if err != nil {
+		return nil, err
+	}
+	// 修改密码会使已发放的访问授权失效
+	fields["password_hash"] = hash
+	return fields, nil
+}
@@ -0,0 +1,226 @@
+package controllers
+
+import (
+	"blog-system/database"
+	"blog-system/models"
+	"blog-system/utils"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// VoteComment 对评论投赞成票或反对票，重复投相同的票不会重复计数，改投时调整两项计数
+// 请求体: value - 1（赞成）或 -1（反对）
+// 参数: c - Gin上下文
+func VoteComment(c *gin.Context) {
+	comment, ok := loadVoteTarget(c)
+	if !ok {
+		return
+	}
+	userId, _ := currentUserID(c)
+
+	var data map[string]interface{}
+	if err := c.ShouldBindJSON(&data); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	raw, _ := data["value"].(float64)
+	value := int(raw)
+	if raw != float64(value) || (value != models.VoteUp && value != models.VoteDown) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "value must be 1 or -1"})
+		return
+	}
+
+	// 计数只根据投票记录实际发生的变化增减：插入、恢复和改投都带有条件，
+	// 并发的重复请求中只有一个会命中，计数与投票记录始终一致
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		vote := models.CommentVote{CommentID: comment.ID, UserID: userId, Value: value, CreatedAt: now, UpdatedAt: now}
+		result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&vote)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 1 {
+			return applyVoteDelta(tx, comment.ID, 0, value)
+		}
+
+		// 已取消的投票被恢复
+		result = tx.Unscoped().Model(&models.CommentVote{}).
+			Where("comment_id = ? AND user_id = ? AND deleted_at IS NOT NULL", comment.ID, userId).
+			Updates(map[string]interface{}{"value": value, "deleted_at": nil, "updated_at": now})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 1 {
+			return applyVoteDelta(tx, comment.ID, 0, value)
+		}
+
+		// 改投另一种票，已投相同的票时不做修改
+		result = tx.Model(&models.CommentVote{}).
+			Where("comment_id = ? AND user_id = ? AND value <> ?", comment.ID, userId, value).
+			Updates(map[string]interface{}{"value": value, "updated_at": now})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 1 {
+			return applyVoteDelta(tx, comment.ID, -value, value)
+		}
+		return nil
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "投票失败"})
+		return
+	}
+
+	respondVotes(c, comment.ID)
+}
+
+// UnvoteComment 取消对评论的投票
+// 参数: c - Gin上下文
+func UnvoteComment(c *gin.Context) {
+	comment, ok := loadVoteTarget(c)
+	if !ok {
+		return
+	}
+	userId, _ := currentUserID(c)
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		for _, value := range []int{models.VoteUp, models.VoteDown} {
+			result := tx.Where("comment_id = ? AND user_id = ? AND value = ?", comment.ID, userId, value).
+				Delete(&models.CommentVote{})
+			if result.Error != nil {
+				return result.Error
+			}
+			if result.RowsAffected == 1 {
+				return applyVoteDelta(tx, comment.ID, value, 0)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "取消投票失败"})
+		return
+	}
+
+	respondVotes(c, comment.ID)
+}
+
+// loadVoteTarget 加载当前用户可以投票的评论，失败时直接写入错误响应
+// 只能对已通过审核且未被隐藏的评论投票，不能给自己的评论投票
+// 参数: c - Gin上下文
+// 返回值: 评论, 是否成功
+func loadVoteTarget(c *gin.Context) (*models.Comment, bool) {
+	userId, ok := currentUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return nil, false
+	}
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的评论ID"})
+		return nil, false
+	}
+
+	var comment models.Comment
+	err = database.DB.Select("id", "user_id", "post_id").
+		Where("status = ? AND hidden_at IS NULL", models.CommentStatusApproved).
+		First(&comment, id).Error
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "评论不存在"})
+		return nil, false
+	}
+	var post models.Post
+	if err := database.DB.Select("id", "user_id", "status", "visibility", "password_hash", "hidden_at").First(&post, comment.PostID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "评论不存在"})
+		return nil, false
+	}
+	if !checkPostAccess(c, &post) {
+		return nil, false
+	}
+	if comment.UserID != nil && *comment.UserID == userId {
+		c.JSON(http.StatusForbidden, gin.H{"error": "不能给自己的评论投票"})
+		return nil, false
+	}
+	return &comment, true
+}
+
+// applyVoteDelta 按一张票的变化原子地更新评论的票数，并重新计算排序得分
+// 计数更新会锁定评论行直到事务结束，随后读取的票数即为本次更新后的结果
+// 参数: tx - 数据库事务, commentID - 评论ID, from - 原来的票（0 表示未投票）, to - 新的票（0 表示取消）
+// 返回值: 错误信息
+func applyVoteDelta(tx *gorm.DB, commentID uint, from, to int) error {
+	var up, down int
+	switch from {
+	case models.VoteUp:
+		up--
+	case models.VoteDown:
+		down--
+	}
+	switch to {
+	case models.VoteUp:
+		up++
+	case models.VoteDown:
+		down++
+	}
+	err := tx.Model(&models.Comment{}).Where("id = ?", commentID).UpdateColumns(map[string]interface{}{
+		"upvotes":   gorm.Expr("upvotes + ?", up),
+		"downvotes": gorm.Expr("downvotes + ?", down),
+	}).Error
+	if err != nil {
+		return err
+	}
+
+	var comment models.Comment
+	if err := tx.Select("id", "upvotes", "downvotes").First(&comment, commentID).Error; err != nil {
+		return err
+	}
+	return tx.Model(&comment).UpdateColumn("vote_score", utils.WilsonLowerBound(comment.Upvotes, comment.Downvotes)).Error
+}
+
+// respondVotes 返回评论最新的投票统计
+func respondVotes(c *gin.Context, commentID uint) {
+	var comment models.Comment
+	if err := database.DB.Select("id", "upvotes", "downvotes", "vote_score").First(&comment, commentID).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "获取投票统计失败"})
+		return
+	}
+	userId, _ := currentUserID(c)
+	if err := fillMyVotes([]*models.Comment{&comment}, userId); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "获取投票统计失败"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"comment_id": comment.ID,
+		"upvotes":    comment.Upvotes,
+		"downvotes":  comment.Downvotes,
+		"score":      comment.VoteScore,
+		"my_vote":    comment.MyVote,
+	})
+}
+
+// fillMyVotes 一次查询为评论填充当前用户的投票
+// 参数: comments - 评论列表, userID - 当前用户ID（0 表示未登录）
+func fillMyVotes(comments []*models.Comment, userID uint) error {
+	if userID == 0 || len(comments) == 0 {
+		return nil
+	}
+	ids := make([]uint, len(comments))
+	for i, comment := range comments {
+		ids[i] = comment.ID
+	}
+	var votes []models.CommentVote
+	if err := database.DB.Select("comment_id", "value").Where("user_id = ? AND comment_id IN ?", userID, ids).Find(&votes).Error; err != nil {
+		return err
+	}
+	mine := make(map[uint]int, len(votes))
+	for _, vote := range votes {
+		mine[vote.CommentID] = vote.Value
+	}
+	for _, comment := range comments {
+		comment.MyVote = mine[comment.ID]
+	}
+	return nil
+}
@@ -29,7 +29,8 @@ func InitDB() error {
 		if dsn == "" {
 			dsn = "blog.db"
 		}
-		dialector = sqlite.Open(dsn)
+		// 使用注册了检索分词函数的驱动
+		dialector = sqlite.New(sqlite.Config{DriverName: sqliteDriverName, DSN: dsn})
 	default:
 		return fmt.Errorf("不支持的数据库驱动: %s", cfg.DBDriver)
 	}
@@ -13,7 +13,31 @@ func AutoMigrate(db *gorm.DB) error {
 		&models.User{},
 		&models.Post{},
 		&models.Comment{},
+		&models.Tag{},
+		&models.Category{},
+		&models.Media{},
+		&models.PostCollaborator{},
+		&models.Series{},
+		&models.SeriesPost{},
+		&models.Reaction{},
+		&models.BookmarkFolder{},
+		&models.Bookmark{},
+		&models.PostViewStat{},
+		&models.HomePlacement{},
+		&models.SpamToken{},
+		&models.Follow{},
+		&models.Report{},
+		&models.Mention{},
+		&models.CommentVote{},
+		&models.UsedChallenge{},
 	)
 
-	return err
+	if err != nil {
+		return err
+	}
+
+	// 发布时间字段新增前的文章视为在创建时发布
+	return db.Model(&models.Post{}).
+		Where("status = ? AND published_at IS NULL", models.PostStatusPublished).
+		UpdateColumn("published_at", gorm.Expr("created_at")).Error
 }
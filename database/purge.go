@@ -0,0 +1,91 @@
+package database
+
+import (
+	"blog-system/models"
+	"gorm.io/gorm"
+	"time"
+)
+
+// purgeBatchSize 每批永久删除的文章数
+const purgeBatchSize = 100
+
+// PurgePosts 永久删除文章及其全部关联数据
+// 参数: tx - 数据库实例（建议在事务中调用）, postIDs - 文章ID列表
+func PurgePosts(tx *gorm.DB, postIDs []uint) error {
+	if len(postIDs) == 0 {
+		return nil
+	}
+
+	// 先解除回复关系，避免自引用外键影响批量删除
+	if err := tx.Unscoped().Model(&models.Comment{}).Where("post_id IN ? AND parent_id IS NOT NULL", postIDs).
+		Update("parent_id", nil).Error; err != nil {
+		return err
+	}
+	comments := tx.Unscoped().Model(&models.Comment{}).Select("id").Where("post_id IN ?", postIDs)
+	if err := tx.Where("(target_type = ? AND target_id IN ?) OR (target_type = ? AND target_id IN (?))",
+		models.ReportTargetPost, postIDs, models.ReportTargetComment, comments).Delete(&models.Report{}).Error; err != nil {
+		return err
+	}
+	if err := tx.Where("post_id IN ?", postIDs).Delete(&models.Mention{}).Error; err != nil {
+		return err
+	}
+	if err := tx.Unscoped().Where("comment_id IN (?)", comments).Delete(&models.CommentVote{}).Error; err != nil {
+		return err
+	}
+	if err := tx.Unscoped().Where("post_id IN ?", postIDs).Delete(&models.Comment{}).Error; err != nil {
+		return err
+	}
+	if err := tx.Exec("DELETE FROM post_tags WHERE post_id IN ?", postIDs).Error; err != nil {
+		return err
+	}
+	if err := tx.Where("post_id IN ?", postIDs).Delete(&models.PostCollaborator{}).Error; err != nil {
+		return err
+	}
+	if err := tx.Where("post_id IN ?", postIDs).Delete(&models.SeriesPost{}).Error; err != nil {
+		return err
+	}
+	if err := tx.Unscoped().Where("post_id IN ?", postIDs).Delete(&models.Reaction{}).Error; err != nil {
+		return err
+	}
+	if err := tx.Unscoped().Where("post_id IN ?", postIDs).Delete(&models.Bookmark{}).Error; err != nil {
+		return err
+	}
+	if err := tx.Where("post_id IN ?", postIDs).Delete(&models.PostViewStat{}).Error; err != nil {
+		return err
+	}
+	if err := tx.Unscoped().Where("post_id IN ?", postIDs).Delete(&models.HomePlacement{}).Error; err != nil {
+		return err
+	}
+	// 媒体文件归上传者所有，只解除与文章的关联
+	if err := tx.Unscoped().Model(&models.Media{}).Where("post_id IN ?", postIDs).Update("post_id", nil).Error; err != nil {
+		return err
+	}
+	return tx.Unscoped().Where("id IN ?", postIDs).Delete(&models.Post{}).Error
+}
+
+// PurgeExpiredPosts 永久删除在回收站中停留超过保留期的文章
+// 参数: db - 数据库实例, before - 删除时间早于该时间的文章会被清理
+// 返回值: 清理的文章数, 错误信息
+func PurgeExpiredPosts(db *gorm.DB, before time.Time) (int, error) {
+	purged := 0
+	for {
+		var ids []uint
+		err := db.Unscoped().Model(&models.Post{}).
+			Where("deleted_at IS NOT NULL AND deleted_at < ?", before).
+			Order("id").Limit(purgeBatchSize).
+			Pluck("id", &ids).Error
+		if err != nil {
+			return purged, err
+		}
+		if len(ids) == 0 {
+			return purged, nil
+		}
+
+		if err := db.Transaction(func(tx *gorm.DB) error {
+			return PurgePosts(tx, ids)
+		}); err != nil {
+			return purged, err
+		}
+		purged += len(ids)
+	}
+}
@@ -0,0 +1,159 @@
+package database
+
+import (
+	"blog-system/utils"
+	"database/sql"
+	"fmt"
+	"github.com/mattn/go-sqlite3"
+	"gorm.io/gorm"
+	"log"
+	"strings"
+)
+
+// 全文检索模式
+const (
+	SearchModeFullText = "fulltext" // MySQL FULLTEXT 索引（ngram 分词）
+	SearchModeFTS5     = "fts5"     // SQLite FTS5 虚拟表
+	SearchModeLike     = "like"     // 不支持全文索引时退化为 LIKE 查询
+)
+
+// SearchMode 当前数据库使用的全文检索模式
+var SearchMode = SearchModeLike
+
+// sqliteDriverName 注册了检索分词函数的 SQLite 驱动名
+const sqliteDriverName = "sqlite3_blog"
+
+func init() {
+	// 每个 SQLite 连接都注册 search_segment 函数，供全文索引触发器调用
+	sql.Register(sqliteDriverName, &sqlite3.SQLiteDriver{
+		ConnectHook: func(conn *sqlite3.SQLiteConn) error {
+			return conn.RegisterFunc("search_segment", utils.SegmentSearchText, true)
+		},
+	})
+}
+
+// ftsTable 需要建立全文索引的表
+type ftsTable struct {
+	table   string   // 源表
+	columns []string // 索引列
+}
+
+var ftsTables = []ftsTable{
+	{table: "posts", columns: []string{"title", "content"}},
+	{table: "comments", columns: []string{"content"}},
+}
+
+// SetupSearch 根据数据库驱动建立全文索引
+// 参数: db - 数据库实例
+func SetupSearch(db *gorm.DB) error {
+	switch db.Dialector.Name() {
+	case "mysql":
+		if err := setupMySQLFullText(db); err != nil {
+			return err
+		}
+		SearchMode = SearchModeFullText
+	case "sqlite":
+		if err := setupSQLiteFTS5(db); err != nil {
+			// 驱动未以 sqlite_fts5 标签编译时没有 FTS5 模块，移除触发器以免写入失败
+			log.Printf("警告: 无法启用 FTS5 全文检索，退化为 LIKE 查询: %v", err)
+			for _, t := range ftsTables {
+				for _, suffix := range []string{"ai", "au", "ad"} {
+					db.Exec(fmt.Sprintf("DROP TRIGGER IF EXISTS %s_fts_%s", t.table, suffix))
+				}
+			}
+			SearchMode = SearchModeLike
+			return nil
+		}
+		SearchMode = SearchModeFTS5
+	default:
+		SearchMode = SearchModeLike
+	}
+
+	log.Printf("全文检索模式: %s", SearchMode)
+	return nil
+}
+
+// setupMySQLFullText 创建使用 ngram 分词器的 FULLTEXT 索引，以支持中文检索
+func setupMySQLFullText(db *gorm.DB) error {
+	for _, t := range ftsTables {
+		index := "ft_" + t.table
+		var count int64
+		err := db.Raw(`SELECT COUNT(*) FROM information_schema.statistics
+			WHERE table_schema = DATABASE() AND table_name = ? AND index_name = ?`, t.table, index).Scan(&count).Error
+		if err != nil {
+			return fmt.Errorf("检查全文索引失败: %w", err)
+		}
+		if count > 0 {
+			continue
+		}
+
+		columns := strings.Join(t.columns, ", ")
+		if err := db.Exec(fmt.Sprintf("ALTER TABLE %s ADD FULLTEXT INDEX %s (%s) WITH PARSER ngram", t.table, index, columns)).Error; err != nil {
+			return fmt.Errorf("创建全文索引 %s 失败: %w", index, err)
+		}
+	}
+	return nil
+}
+
+// setupSQLiteFTS5 创建 FTS5 虚拟表并通过触发器与源表同步
+// 写入索引前先用 search_segment 切分中文，已软删除的记录不进入索引
+func setupSQLiteFTS5(db *gorm.DB) error {
+	for _, t := range ftsTables {
+		fts := t.table + "_fts"
+		columns := strings.Join(t.columns, ", ")
+		segmented := segmentedColumns(t.columns, "new.")
+
+		// 触发器缺失说明索引是新建的，或之前曾退化为 LIKE 模式导致索引落后于源表
+		var synced int64
+		if err := db.Raw("SELECT COUNT(*) FROM sqlite_master WHERE type = 'trigger' AND name = ?", t.table+"_fts_ai").Scan(&synced).Error; err != nil {
+			return err
+		}
+
+		statements := []string{
+			fmt.Sprintf("CREATE VIRTUAL TABLE IF NOT EXISTS %s USING fts5(%s, tokenize = 'unicode61')", fts, columns),
+			fmt.Sprintf(`CREATE TRIGGER IF NOT EXISTS %[1]s_fts_ai AFTER INSERT ON %[1]s WHEN new.deleted_at IS NULL BEGIN
+				INSERT INTO %[2]s (rowid, %[3]s) VALUES (new.id, %[4]s);
+			END`, t.table, fts, columns, segmented),
+			fmt.Sprintf(`CREATE TRIGGER IF NOT EXISTS %[1]s_fts_au AFTER UPDATE OF %[3]s, deleted_at ON %[1]s BEGIN
+				DELETE FROM %[2]s WHERE rowid = old.id;
+				INSERT INTO %[2]s (rowid, %[3]s) SELECT new.id, %[4]s WHERE new.deleted_at IS NULL;
+			END`, t.table, fts, columns, segmented),
+			fmt.Sprintf(`CREATE TRIGGER IF NOT EXISTS %[1]s_fts_ad AFTER DELETE ON %[1]s BEGIN
+				DELETE FROM %[2]s WHERE rowid = old.id;
+			END`, t.table, fts),
+		}
+		for _, stmt := range statements {
+			if err := db.Exec(stmt).Error; err != nil {
+				return err
+			}
+		}
+
+		// 虚拟表已存在时 CREATE 不会报错，需实际查询确认 FTS5 模块可用
+		if err := db.Exec(fmt.Sprintf("SELECT COUNT(*) FROM %s", fts)).Error; err != nil {
+			return err
+		}
+
+		// 为已有数据重建索引
+		if synced == 0 {
+			if err := db.Exec(fmt.Sprintf("DELETE FROM %s", fts)).Error; err != nil {
+				return err
+			}
+			rebuild := fmt.Sprintf("INSERT INTO %s (rowid, %s) SELECT id, %s FROM %s WHERE deleted_at IS NULL",
+				fts, columns, segmentedColumns(t.columns, ""), t.table)
+			if err := db.Exec(rebuild).Error; err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// segmentedColumns 生成对各列调用 search_segment 的表达式列表
+// 参数: columns - 列名, alias - 列名前缀（如触发器中的 "new."）
+func segmentedColumns(columns []string, alias string) string {
+	exprs := make([]string, len(columns))
+	for i, column := range columns {
+		exprs[i] = "search_segment(" + alias + column + ")"
+	}
+	return strings.Join(exprs, ", ")
+}
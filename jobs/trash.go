@@ -0,0 +1,34 @@
+package jobs
+
+import (
+	"blog-system/database"
+	"log"
+	"time"
+)
+
+// trashPurgeInterval 回收站清理任务的执行间隔
+const trashPurgeInterval = time.Hour
+
+// StartTrashPurger 启动回收站定时清理任务
+// 参数: retention - 回收站保留时长，为0时不启动
+func StartTrashPurger(retention time.Duration) {
+	if retention <= 0 {
+		log.Println("回收站自动清理已关闭")
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(trashPurgeInterval)
+		defer ticker.Stop()
+
+		for {
+			purged, err := database.PurgeExpiredPosts(database.DB, time.Now().Add(-retention))
+			if err != nil {
+				log.Printf("回收站清理失败: %v", err)
+			} else if purged > 0 {
+				log.Printf("回收站清理完成，永久删除 %d 篇文章", purged)
+			}
+			<-ticker.C
+		}
+	}()
+}
@@ -0,0 +1,106 @@
+package jobs
+
+import (
+	"blog-system/database"
+	"blog-system/models"
+	"fmt"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"log"
+	"time"
+)
+
+// 浏览量统计参数
+const (
+	viewQueueSize     = 4096             // 待处理访问事件的缓冲区大小
+	viewFlushInterval = 10 * time.Second // 写入数据库的间隔
+)
+
+// viewEvent 一次文章访问
+type viewEvent struct {
+	postID  uint
+	visitor string
+	at      time.Time
+}
+
+// viewBucket 按文章和日期聚合的浏览量
+type viewBucket struct {
+	postID uint
+	day    string
+}
+
+// viewQueue 访问事件队列，统计任务未启动时为 nil
+var viewQueue chan viewEvent
+
+// StartViewRecorder 启动浏览量统计任务
+// 访问事件在内存中去重并按天聚合，定期批量写入数据库，不占用读请求的时间
+// 参数: dedupeWindow - 同一访客重复访问同一文章不计数的时间窗口
+func StartViewRecorder(dedupeWindow time.Duration) {
+	viewQueue = make(chan viewEvent, viewQueueSize)
+	go runViewRecorder(viewQueue, dedupeWindow)
+}
+
+// RecordView 记录一次文章访问，不会阻塞调用方；队列已满时丢弃该事件
+// 参数: postID - 文章ID, visitor - 访客标识
+func RecordView(postID uint, visitor string) {
+	if viewQueue == nil {
+		return
+	}
+	select {
+	case viewQueue <- viewEvent{postID: postID, visitor: visitor, at: time.Now()}:
+	default:
+	}
+}
+
+// runViewRecorder 消费访问事件并定期写入数据库
+func runViewRecorder(queue <-chan viewEvent, dedupeWindow time.Duration) {
+	seen := make(map[string]time.Time)
+	pending := make(map[viewBucket]int64)
+	ticker := time.NewTicker(viewFlushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case event := <-queue:
+			key := fmt.Sprintf("%d:%s", event.postID, event.visitor)
+			if last, ok := seen[key]; ok && event.at.Sub(last) < dedupeWindow {
+				continue
+			}
+			seen[key] = event.at
+			pending[viewBucket{postID: event.postID, day: event.at.UTC().Format("2006-01-02")}]++
+
+		case now := <-ticker.C:
+			// 写入失败时保留计数，下次重试
+			if err := flushViews(pending); err != nil {
+				log.Printf("写入浏览量失败: %v", err)
+			} else {
+				pending = make(map[viewBucket]int64)
+			}
+			for key, last := range seen {
+				if now.Sub(last) >= dedupeWindow {
+					delete(seen, key)
+				}
+			}
+		}
+	}
+}
+
+// flushViews 将聚合的浏览量累加到每日统计表
+func flushViews(pending map[viewBucket]int64) error {
+	if len(pending) == 0 {
+		return nil
+	}
+	return database.DB.Transaction(func(tx *gorm.DB) error {
+		for bucket, views := range pending {
+			stat := models.PostViewStat{PostID: bucket.postID, Day: bucket.day, Views: views}
+			err := tx.Clauses(clause.OnConflict{
+				Columns:   []clause.Column{{Name: "post_id"}, {Name: "day"}},
+				DoUpdates: clause.Assignments(map[string]interface{}{"views": gorm.Expr("post_view_stats.views + ?", views)}),
+			}).Create(&stat).Error
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
@@ -80,6 +80,25 @@ func AuthMiddleware() gin.HandlerFunc {
 			return
 		}
 
+		// 已停用的账号不能继续使用已签发的令牌
+		var user models.User
+		if err := database.DB.Select("id", "suspended_at").First(&user, mc.UserID).Error; err != nil {
+			c.JSON(http.StatusUnauthorized, gin.H{
+				"code": 2006,
+				"msg":  "Invalid user in token",
+			})
+			c.Abort()
+			return
+		}
+		if user.SuspendedAt != nil {
+			c.JSON(http.StatusForbidden, gin.H{
+				"code": 2007,
+				"msg":  "Account suspended",
+			})
+			c.Abort()
+			return
+		}
+
 		// 将当前请求的username信息保存到请求的上下文c上
 		c.Set("username", mc.Username)
 		c.Set("userid", mc.UserID)
@@ -87,9 +106,49 @@ func AuthMiddleware() gin.HandlerFunc {
 	}
 }
 
-// AuthorizePostOwner 验证文章所有者中间件
+// OptionalAuthMiddleware 可选的JWT认证中间件
+// 未携带 Authorization 头时按匿名用户放行，携带时与 AuthMiddleware 一样校验
+// 返回值: Gin处理函数
+func OptionalAuthMiddleware() gin.HandlerFunc {
+	auth := AuthMiddleware()
+	return func(c *gin.Context) {
+		if c.Request.Header.Get("Authorization") == "" {
+			c.Next()
+			return
+		}
+		auth(c)
+	}
+}
+
+// AuthorizePostOwner 验证文章所有者中间件（作者或 owner 角色的协作者）
 // 返回值: Gin处理函数
 func AuthorizePostOwner() gin.HandlerFunc {
+	return authorizePostRole(models.CollaboratorOwner, false)
+}
+
+// AuthorizePostEditor 验证文章编辑权限中间件（所有者或合著者）
+// 返回值: Gin处理函数
+func AuthorizePostEditor() gin.HandlerFunc {
+	return authorizePostRole(models.CollaboratorCoAuthor, false)
+}
+
+// AuthorizePostCollaborator 验证文章协作者中间件（任意角色）
+// 返回值: Gin处理函数
+func AuthorizePostCollaborator() gin.HandlerFunc {
+	return authorizePostRole(models.CollaboratorReviewer, false)
+}
+
+// AuthorizeTrashedPostOwner 验证文章所有者中间件，包括回收站中已软删除的文章
+// 返回值: Gin处理函数
+func AuthorizeTrashedPostOwner() gin.HandlerFunc {
+	return authorizePostRole(models.CollaboratorOwner, true)
+}
+
+// authorizePostRole 验证当前用户在文章中的协作角色
+// 通过后将角色写入上下文的 post_role 中
+// 参数: minRole - 所需的最低角色, includeTrashed - 是否包含已软删除的文章
+// 返回值: Gin处理函数
+func authorizePostRole(minRole string, includeTrashed bool) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		// 1. 从URL参数获取文章ID
 		param := c.Param("id")
@@ -116,9 +175,13 @@ func AuthorizePostOwner() gin.HandlerFunc {
 			return
 		}
 
-		// 4. 查询数据库验证文章所有者
+		// 4. 查询数据库中的文章
 		var post models.Post
-		if err := database.DB.First(&post, id).Error; err != nil {
+		query := database.DB
+		if includeTrashed {
+			query = query.Unscoped()
+		}
+		if err := query.First(&post, id).Error; err != nil {
 			// 更精确的错误处理
 			if err == gorm.ErrRecordNotFound {
 				c.JSON(http.StatusNotFound, gin.H{"error": "Post not found"})
@@ -129,14 +192,154 @@ func AuthorizePostOwner() gin.HandlerFunc {
 			return
 		}
 
-		// 5. 验证当前用户是否是文章作者
-		if post.UserID != userId {
-			c.JSON(http.StatusForbidden, gin.H{"error": "You are not the owner of this post"})
+		// 5. 验证当前用户是否是文章作者或具有足够权限的协作者
+		role, err := post.RoleOf(database.DB, userId)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+			c.Abort()
+			return
+		}
+		if !models.RoleAtLeast(role, minRole) {
+			switch minRole {
+			case models.CollaboratorOwner:
+				c.JSON(http.StatusForbidden, gin.H{"error": "You are not the owner of this post"})
+			case models.CollaboratorCoAuthor:
+				c.JSON(http.StatusForbidden, gin.H{"error": "You are not allowed to edit this post"})
+			default:
+				c.JSON(http.StatusForbidden, gin.H{"error": "You are not a collaborator of this post"})
+			}
 			c.Abort()
 			return
 		}
 
 		// 继续执行下一个处理程序
+		c.Set("post_role", role)
+		c.Next()
+	}
+}
+
+// AuthorizeCommentOwner 验证评论作者中间件
+// 返回值: Gin处理函数
+func AuthorizeCommentOwner() gin.HandlerFunc {
+	return authorizeComment(false)
+}
+
+// AuthorizeCommentModerator 验证评论删除权限中间件（评论作者或文章所有者）
+// 返回值: Gin处理函数
+func AuthorizeCommentModerator() gin.HandlerFunc {
+	return authorizeComment(true)
+}
+
+// authorizeComment 验证当前用户对评论的权限
+// 通过后将身份写入上下文的 comment_role 中：author 表示评论作者，post_owner 表示文章所有者
+// 参数: allowPostOwner - 是否允许文章所有者操作他人的评论
+// 返回值: Gin处理函数
+func authorizeComment(allowPostOwner bool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		// 1. 从URL参数获取评论ID
+		id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+		if err != nil || id == 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid comment ID"})
+			c.Abort()
+			return
+		}
+
+		// 2. 从上下文中获取用户ID
+		userIdValue, exists := c.Get("userid")
+		if !exists {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+			c.Abort()
+			return
+		}
+		userId, ok := userIdValue.(uint)
+		if !ok || userId == 0 {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Invalid user ID"})
+			c.Abort()
+			return
+		}
+
+		// 3. 查询评论，文章移入回收站时评论随之软删除
+		var comment models.Comment
+		if err := database.DB.Select("id", "user_id", "post_id").First(&comment, id).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				c.JSON(http.StatusNotFound, gin.H{"error": "Comment not found"})
+			} else {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+			}
+			c.Abort()
+			return
+		}
+
+		// 4. 评论作者始终有权限，文章所有者只在允许时有权限
+		if comment.UserID != nil && *comment.UserID == userId {
+			c.Set("comment_role", "author")
+			c.Next()
+			return
+		}
+		if allowPostOwner {
+			var post models.Post
+			if err := database.DB.Select("id", "user_id").First(&post, comment.PostID).Error; err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+				c.Abort()
+				return
+			}
+			role, err := post.RoleOf(database.DB, userId)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+				c.Abort()
+				return
+			}
+			if role == models.CollaboratorOwner {
+				c.Set("comment_role", "post_owner")
+				c.Next()
+				return
+			}
+		}
+
+		c.JSON(http.StatusForbidden, gin.H{"error": "You are not the author of this comment"})
+		c.Abort()
+	}
+}
+
+// AuthorizeAdmin 验证管理员权限中间件
+// 需放在 AuthMiddleware 之后，角色以数据库中存储的用户为准
+// 返回值: Gin处理函数
+func AuthorizeAdmin() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		// 1. 从上下文中获取用户ID
+		userIdValue, exists := c.Get("userid")
+		if !exists {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+			c.Abort()
+			return
+		}
+
+		userId, ok := userIdValue.(uint)
+		if !ok || userId == 0 {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Invalid user ID"})
+			c.Abort()
+			return
+		}
+
+		// 2. 查询数据库中的用户角色
+		var user models.User
+		if err := database.DB.Select("id", "role").First(&user, userId).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found"})
+			} else {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+			}
+			c.Abort()
+			return
+		}
+
+		// 3. 验证是否为管理员
+		if user.Role != models.RoleAdmin {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Admin privileges required"})
+			c.Abort()
+			return
+		}
+
 		c.Next()
 	}
 }
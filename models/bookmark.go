@@ -0,0 +1,27 @@
+package models
+
+import (
+	"gorm.io/gorm"
+	"time"
+)
+
+// BookmarkFolder 收藏夹，用于对收藏的文章分组
+type BookmarkFolder struct {
+	ID        uint      `gorm:"primarykey" json:"id"`
+	UserID    uint      `gorm:"uniqueIndex:idx_user_folder;not null" json:"user_id"`      // 所属用户ID
+	Name      string    `gorm:"uniqueIndex:idx_user_folder;size:64;not null" json:"name"` // 收藏夹名称，同一用户下唯一
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Bookmark 用户收藏的文章，每个用户对每篇文章最多一条
+// 取消收藏时软删除，再次收藏时恢复原记录，以便根据更新/删除时间生成 Last-Modified
+type Bookmark struct {
+	ID        uint           `gorm:"primarykey" json:"id"`
+	UserID    uint           `gorm:"uniqueIndex:idx_user_post;not null" json:"user_id"` // 用户ID
+	PostID    uint           `gorm:"uniqueIndex:idx_user_post;not null" json:"post_id"` // 文章ID
+	FolderID  *uint          `gorm:"index" json:"folder_id"`                            // 所在收藏夹，为空表示未分组
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
+}
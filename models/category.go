@@ -0,0 +1,12 @@
+package models
+
+import "gorm.io/gorm"
+
+type Category struct {
+	gorm.Model
+	Name     string     `gorm:"size:64;not null" json:"name"`                  // 分类名称，同级唯一
+	ParentID *uint      `gorm:"index" json:"parent_id"`                        // 父分类ID，顶级分类为空
+	Parent   *Category  `json:"-"`                                             // 关联父分类
+	Children []Category `gorm:"foreignKey:ParentID" json:"children,omitempty"` // 子分类
+	Sort     int        `gorm:"not null;default:0" json:"sort"`                // 同级排序，越小越靠前
+}
@@ -0,0 +1,10 @@
+package models
+
+import "time"
+
+// UsedChallenge 已用于提交游客评论的工作量证明题目，保证每个题目在所有实例中只能使用一次
+// 题目过期后即无法通过验证，记录可以随之清理
+type UsedChallenge struct {
+	ID        string    `gorm:"primaryKey;size:64"` // 题目ID
+	ExpiresAt time.Time `gorm:"not null;index"`     // 题目的过期时间
+}
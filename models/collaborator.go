@@ -0,0 +1,63 @@
+package models
+
+import (
+	"errors"
+	"gorm.io/gorm"
+	"time"
+)
+
+// 文章协作者角色，权限依次递减
+const (
+	CollaboratorOwner    = "owner"    // 所有者：可删除文章、管理协作者、转让文章
+	CollaboratorCoAuthor = "coauthor" // 合著者：可编辑文章
+	CollaboratorReviewer = "reviewer" // 审阅者：可查看草稿
+)
+
+// collaboratorRank 角色权限等级
+var collaboratorRank = map[string]int{
+	CollaboratorReviewer: 1,
+	CollaboratorCoAuthor: 2,
+	CollaboratorOwner:    3,
+}
+
+// ValidCollaboratorRole 判断角色是否有效
+func ValidCollaboratorRole(role string) bool {
+	return collaboratorRank[role] > 0
+}
+
+// RoleAtLeast 判断角色是否具有不低于 min 的权限，空角色没有任何权限
+func RoleAtLeast(role, min string) bool {
+	return collaboratorRank[role] > 0 && collaboratorRank[role] >= collaboratorRank[min]
+}
+
+type PostCollaborator struct {
+	ID        uint         `gorm:"primarykey" json:"-"`
+	PostID    uint         `gorm:"uniqueIndex:idx_post_user;not null" json:"post_id"` // 文章ID
+	UserID    uint         `gorm:"uniqueIndex:idx_post_user;not null" json:"user_id"` // 协作者ID
+	User      *UserSummary `json:"user,omitempty"`                                    // 协作者信息
+	Role      string       `gorm:"size:20;not null" json:"role"`                      // 协作角色
+	CreatedAt time.Time    `json:"created_at"`
+}
+
+// RoleOf 获取用户在文章中的角色
+// 文章作者始终视为所有者，其余用户按协作者记录确定角色
+// 参数: tx - 数据库实例, userID - 用户ID（0 表示未登录）
+// 返回值: 角色，无权限时为空字符串, 错误信息
+func (p *Post) RoleOf(tx *gorm.DB, userID uint) (string, error) {
+	if userID == 0 {
+		return "", nil
+	}
+	if p.UserID == userID {
+		return CollaboratorOwner, nil
+	}
+
+	var collaborator PostCollaborator
+	err := tx.Where("post_id = ? AND user_id = ?", p.ID, userID).First(&collaborator).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	return collaborator.Role, nil
+}
@@ -1,12 +1,74 @@
 package models
 
-import "gorm.io/gorm"
+import (
+	"gorm.io/gorm"
+	"time"
+)
+
+// 评论审核状态
+const (
+	CommentStatusPending  = "pending"  // 待审核，仅评论作者可见
+	CommentStatusApproved = "approved" // 已通过
+	CommentStatusRejected = "rejected" // 已拒绝，仅评论作者可见
+)
+
+// 评论审核模式
+const (
+	ModerationOff       = "off"        // 不审核
+	ModerationFirstTime = "first_time" // 仅审核首次评论的用户
+	ModerationAll       = "all"        // 审核全部评论
+)
+
+// ValidModerationMode 判断评论审核模式是否有效
+func ValidModerationMode(mode string) bool {
+	return mode == ModerationOff || mode == ModerationFirstTime || mode == ModerationAll
+}
+
+// 文章评论策略
+const (
+	CommentPolicyOpen       = "open"       // 允许评论（游客还需文章允许游客评论）
+	CommentPolicyClosed     = "closed"     // 关闭评论
+	CommentPolicyRegistered = "registered" // 仅登录用户
+	CommentPolicyFollowers  = "followers"  // 仅作者的关注者
+)
+
+// ValidCommentPolicy 判断评论策略是否有效
+func ValidCommentPolicy(policy string) bool {
+	return policy == CommentPolicyOpen || policy == CommentPolicyClosed ||
+		policy == CommentPolicyRegistered || policy == CommentPolicyFollowers
+}
+
+// CommentAccess 当前用户能否评论文章
+type CommentAccess struct {
+	Policy   string     `json:"policy"`              // 文章设置的评论策略
+	ClosesAt *time.Time `json:"closes_at,omitempty"` // 评论自动关闭的时间，未开启自动关闭时为空
+	Allowed  bool       `json:"allowed"`             // 当前用户能否发表评论
+	Reason   string     `json:"reason,omitempty"`    // 不能评论的原因：closed、auto_closed、login_required 或 followers_only
+}
 
 type Comment struct {
 	gorm.Model
-	Content string `gorm:"not null"` // 评论内容
-	UserID  uint   // 评论者ID
-	User    User   // 关联评论者
-	PostID  uint   // 关联文章ID
-	Post    Post   // 关联文章
+	Content     string         `gorm:"not null"`  // 评论内容
+	EditedAt    *time.Time     `json:"edited_at"` // 最后一次编辑内容的时间，未编辑过为空
+	UserID      *uint          // 评论者ID，游客评论为空
+	GuestName   string         `gorm:"size:64" json:"-"`  // 游客昵称，通过 author 展示
+	GuestEmail  string         `gorm:"size:255" json:"-"` // 游客邮箱，仅保存不展示
+	User        User           // 关联评论者
+	Author      *UserSummary   `gorm:"-" json:"author,omitempty"` // 评论者摘要
+	PostID      uint           // 关联文章ID
+	Post        Post           // 关联文章
+	ParentID    *uint          `gorm:"index" json:"parent_id"`                                // 回复的父评论ID，顶级评论为空
+	Parent      *Comment       `json:"-"`                                                     // 关联父评论
+	Depth       int            `gorm:"not null;default:0" json:"depth"`                       // 嵌套层级，顶级评论为 0
+	Status      string         `gorm:"size:20;not null;default:approved;index" json:"status"` // 审核状态
+	SpamScore   float64        `gorm:"not null;default:0" json:"-"`                           // 垃圾评论过滤得分
+	SpamReasons string         `gorm:"type:text" json:"-"`                                    // 垃圾评论过滤命中的原因
+	TrainedAs   string         `gorm:"size:10" json:"-"`                                      // 审核结果用于训练分类器时的标签
+	HiddenAt    *time.Time     `gorm:"index" json:"hidden_at,omitempty"`                      // 因举报被自动隐藏的时间
+	Upvotes     int64          `gorm:"not null;default:0" json:"upvotes"`                     // 赞成票数
+	Downvotes   int64          `gorm:"not null;default:0" json:"downvotes"`                   // 反对票数
+	VoteScore   float64        `gorm:"not null;default:0" json:"score"`                       // 投票的 Wilson 置信下界，用于 score 排序
+	MyVote      int            `gorm:"-" json:"my_vote,omitempty"`                            // 当前用户的投票：1、-1，未投票时省略
+	Mentions    []MentionRange `gorm:"-" json:"mentions,omitempty"`                           // 内容中的 @提及
+	Replies     []Comment      `gorm:"foreignKey:ParentID" json:"replies,omitempty"`          // 回复，仅在树形结构中返回
 }
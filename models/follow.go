@@ -0,0 +1,10 @@
+package models
+
+import "time"
+
+// Follow 用户之间的关注关系
+type Follow struct {
+	FollowerID uint      `gorm:"primaryKey;autoIncrement:false" json:"follower_id"`       // 关注者ID
+	FolloweeID uint      `gorm:"primaryKey;autoIncrement:false;index" json:"followee_id"` // 被关注者ID
+	CreatedAt  time.Time `json:"created_at"`
+}
@@ -0,0 +1,31 @@
+package models
+
+import (
+	"gorm.io/gorm"
+	"time"
+)
+
+// 首页推荐位
+const (
+	SectionPinned   = "pinned"   // 置顶
+	SectionFeatured = "featured" // 精选
+)
+
+// ValidHomeSection 判断首页推荐位是否有效
+func ValidHomeSection(section string) bool {
+	return section == SectionPinned || section == SectionFeatured
+}
+
+// HomePlacement 文章在首页推荐位中的位置，每篇文章在每个推荐位中最多一条
+// 移除时软删除，再次添加时恢复原记录，以便根据更新/删除时间生成 Last-Modified
+type HomePlacement struct {
+	ID        uint           `gorm:"primarykey" json:"id"`
+	PostID    uint           `gorm:"uniqueIndex:idx_post_section;not null" json:"post_id"`         // 文章ID
+	Section   string         `gorm:"uniqueIndex:idx_post_section;size:20;not null" json:"section"` // 推荐位：pinned 或 featured
+	Position  int            `gorm:"not null;default:0" json:"position"`                           // 排序，数值小的在前
+	ExpiresAt *time.Time     `gorm:"index" json:"expires_at"`                                      // 过期时间，为空表示长期有效
+	UserID    uint           `gorm:"not null" json:"user_id"`                                      // 最后设置的编辑ID
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
+}
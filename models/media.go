@@ -0,0 +1,19 @@
+package models
+
+import "gorm.io/gorm"
+
+type Media struct {
+	gorm.Model
+	UserID       uint   `gorm:"index;not null" json:"user_id"`     // 上传者ID
+	User         User   `json:"-"`                                 // 关联上传者
+	PostID       *uint  `gorm:"index" json:"post_id"`              // 关联文章ID，可为空
+	FileName     string `gorm:"size:255" json:"file_name"`         // 原始文件名
+	StorageKey   string `gorm:"size:255;not null" json:"-"`        // 存储后端中的文件路径
+	ThumbnailKey string `gorm:"size:255" json:"-"`                 // 缩略图路径
+	MimeType     string `gorm:"size:64;not null" json:"mime_type"` // 嗅探得到的 MIME 类型
+	Size         int64  `json:"size"`                              // 处理后的文件大小（字节）
+	Width        int    `json:"width"`                             // 宽度
+	Height       int    `json:"height"`                            // 高度
+	URL          string `gorm:"-" json:"url"`                      // 访问地址，由存储后端生成
+	ThumbnailURL string `gorm:"-" json:"thumbnail_url"`            // 缩略图访问地址
+}
@@ -0,0 +1,30 @@
+package models
+
+import "time"
+
+// 提及所在的内容类型
+const (
+	MentionSourcePost    = "post"    // 文章
+	MentionSourceComment = "comment" // 评论
+)
+
+// Mention 文章或评论中对用户的一处 @提及，内容修改时整体重建
+type Mention struct {
+	ID         uint      `gorm:"primarykey" json:"-"`
+	SourceType string    `gorm:"size:20;not null;index:idx_mention_source" json:"source_type"` // 所在内容类型
+	SourceID   uint      `gorm:"not null;index:idx_mention_source" json:"source_id"`           // 所在内容ID
+	PostID     uint      `gorm:"not null;index" json:"post_id"`                                // 所属文章ID
+	UserID     uint      `gorm:"not null;index" json:"user_id"`                                // 被提及的用户ID
+	AuthorID   *uint     `json:"author_id"`                                                    // 提及者ID，游客评论为空
+	Start      int       `gorm:"column:start_pos;not null" json:"start"`                       // @ 在内容中的位置（按 Unicode 字符计算）
+	End        int       `gorm:"column:end_pos;not null" json:"end"`                           // 用户名结束位置（不含）
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// MentionRange 响应中内容里的一处提及
+type MentionRange struct {
+	UserID   uint   `json:"user_id"`  // 被提及的用户ID
+	Username string `json:"username"` // 被提及的用户名
+	Start    int    `json:"start"`    // @ 在内容中的位置（按 Unicode 字符计算）
+	End      int    `json:"end"`      // 用户名结束位置（不含）
+}
@@ -1,12 +1,96 @@
 package models
 
-import "gorm.io/gorm"
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"gorm.io/gorm"
+	"time"
+)
+
+// 文章状态
+const (
+	PostStatusDraft     = "draft"     // 草稿，仅作者和协作者可见
+	PostStatusPublished = "published" // 已发布
+)
+
+// 文章可见性
+const (
+	VisibilityPublic   = "public"   // 公开
+	VisibilityUnlisted = "unlisted" // 不公开列出，知道链接即可访问
+	VisibilityPrivate  = "private"  // 仅作者和协作者可见
+	VisibilityPassword = "password" // 输入密码后可见，否则只返回摘要
+)
 
 type Post struct {
 	gorm.Model
-	Title    string    `gorm:"not null" form:"title" json:"title" binding:"required"`     // 文章标题
-	Content  string    `gorm:"not null" form:"content" json:"content" binding:"required"` // 文章内容
-	UserID   uint      // 作者ID
-	User     User      // 关联作者
-	Comments []Comment // 文章关联的评论
+	Title             string             `gorm:"not null" form:"title" json:"title" binding:"required"`     // 文章标题
+	Content           string             `gorm:"not null" form:"content" json:"content" binding:"required"` // 文章内容（Markdown）
+	ContentHTML       string             `json:"content_html"`                                              // 渲染并净化后的HTML缓存
+	TOC               TOC                `gorm:"type:text" json:"toc"`                                      // 由标题生成的目录
+	UserID            uint               // 作者ID
+	User              User               // 关联作者
+	Comments          []Comment          // 文章关联的评论
+	Tags              []Tag              `gorm:"many2many:post_tags;" json:"tags"`                        // 文章标签
+	CategoryID        *uint              `gorm:"index" json:"category_id"`                                // 所属分类ID
+	Category          *Category          `json:"category,omitempty"`                                      // 关联分类
+	Media             []Media            `json:"media,omitempty"`                                         // 文章关联的媒体文件
+	Status            string             `gorm:"size:20;not null;default:published;index" json:"status"`  // 文章状态
+	PublishedAt       *time.Time         `json:"published_at"`                                            // 首次发布时间
+	Visibility        string             `gorm:"size:20;not null;default:public;index" json:"visibility"` // 可见性
+	PasswordHash      string             `gorm:"size:100" json:"-"`                                       // 访问密码哈希，仅密码保护时使用
+	Locked            bool               `gorm:"-" json:"locked,omitempty"`                               // 是否因缺少访问授权只返回摘要
+	Teaser            string             `gorm:"-" json:"teaser,omitempty"`                               // 未授权时返回的摘要
+	Reactions         map[string]int64   `gorm:"-" json:"reactions"`                                      // 各类反应的数量
+	MyReactions       []string           `gorm:"-" json:"my_reactions"`                                   // 当前用户的反应，未登录时为 null
+	Bookmarked        *bool              `gorm:"-" json:"bookmarked,omitempty"`                           // 当前用户是否已收藏，未登录时不返回
+	Collaborators     []PostCollaborator `json:"collaborators,omitempty"`                                 // 协作者
+	Series            *SeriesNav         `gorm:"-" json:"series,omitempty"`                               // 所属系列的导航信息
+	CommentModeration string             `gorm:"size:20;not null;default:''" json:"comment_moderation"`   // 评论审核模式，为空时使用站点设置
+	GuestComments     bool               `gorm:"not null;default:false" json:"guest_comments"`            // 是否允许游客评论
+	CommentPolicy     string             `gorm:"size:20;not null;default:open" json:"comment_policy"`     // 评论策略
+	CommentAccess     *CommentAccess     `gorm:"-" json:"comment_access,omitempty"`                       // 当前用户的评论权限，仅在文章详情中返回
+	HiddenAt          *time.Time         `gorm:"index" json:"hidden_at,omitempty"`                        // 因举报被自动隐藏的时间
+	Mentions          []MentionRange     `gorm:"-" json:"mentions,omitempty"`                             // 正文中的 @提及
+	Version           uint               `gorm:"not null;default:1" json:"version"`                       // 版本号，每次更新递增，用于乐观并发控制
+}
+
+// TOCEntry 目录项
+type TOCEntry struct {
+	Level int    `json:"level"` // 标题级别 1-6
+	Text  string `json:"text"`  // 标题文本
+	ID    string `json:"id"`    // 标题锚点
+}
+
+// TOC 文章目录，以 JSON 形式存储在数据库中
+type TOC []TOCEntry
+
+// Value 实现 driver.Valuer 接口
+func (t TOC) Value() (driver.Value, error) {
+	if t == nil {
+		return "[]", nil
+	}
+	data, err := json.Marshal(t)
+	return string(data), err
+}
+
+// Scan 实现 sql.Scanner 接口
+func (t *TOC) Scan(value interface{}) error {
+	var data []byte
+	switch v := value.(type) {
+	case nil:
+		*t = TOC{}
+		return nil
+	case []byte:
+		data = v
+	case string:
+		data = []byte(v)
+	default:
+		return fmt.Errorf("unsupported TOC type: %T", value)
+	}
+	if len(data) == 0 {
+		*t = TOC{}
+		return nil
+	}
+	return json.Unmarshal(data, t)
 }
@@ -0,0 +1,9 @@
+package models
+
+// PostViewStat 文章每日浏览量，按 UTC 日期聚合
+type PostViewStat struct {
+	ID     uint   `gorm:"primarykey" json:"-"`
+	PostID uint   `gorm:"uniqueIndex:idx_post_day;not null" json:"post_id"`     // 文章ID
+	Day    string `gorm:"uniqueIndex:idx_post_day;size:10;not null" json:"day"` // 日期，格式 2006-01-02
+	Views  int64  `gorm:"not null;default:0" json:"views"`                      // 去重后的浏览次数
+}
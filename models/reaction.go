@@ -0,0 +1,31 @@
+package models
+
+import (
+	"gorm.io/gorm"
+	"time"
+)
+
+// ReactionTypes 支持的反应类型
+var ReactionTypes = []string{"like", "love", "insightful", "celebrate", "funny"}
+
+// ValidReactionType 判断反应类型是否有效
+func ValidReactionType(reactionType string) bool {
+	for _, t := range ReactionTypes {
+		if t == reactionType {
+			return true
+		}
+	}
+	return false
+}
+
+// Reaction 用户对文章的反应，每个用户对每篇文章的每种反应最多一条
+// 取消反应时软删除，再次添加时恢复原记录，以便根据更新/删除时间生成 Last-Modified
+type Reaction struct {
+	ID        uint           `gorm:"primarykey" json:"-"`
+	PostID    uint           `gorm:"uniqueIndex:idx_post_user_type;not null" json:"post_id"`       // 文章ID
+	UserID    uint           `gorm:"uniqueIndex:idx_post_user_type;not null;index" json:"user_id"` // 用户ID
+	Type      string         `gorm:"uniqueIndex:idx_post_user_type;size:20;not null" json:"type"`  // 反应类型
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
+}
@@ -0,0 +1,52 @@
+package models
+
+import "time"
+
+// 举报对象类型
+const (
+	ReportTargetPost    = "post"    // 文章
+	ReportTargetComment = "comment" // 评论
+)
+
+// 举报状态
+const (
+	ReportStatusOpen      = "open"      // 待处理
+	ReportStatusDismissed = "dismissed" // 已驳回
+	ReportStatusResolved  = "resolved"  // 已处理（删除内容或停用作者）
+)
+
+// 举报处理方式
+const (
+	ReportActionDismiss = "dismiss" // 驳回举报，恢复被自动隐藏的内容
+	ReportActionRemove  = "remove"  // 删除内容
+	ReportActionSuspend = "suspend" // 删除内容并停用作者账号
+)
+
+// ReportCategories 举报原因分类
+var ReportCategories = []string{"spam", "harassment", "hate", "sexual", "violence", "misinformation", "other"}
+
+// ValidReportCategory 判断举报原因分类是否有效
+func ValidReportCategory(category string) bool {
+	for _, c := range ReportCategories {
+		if c == category {
+			return true
+		}
+	}
+	return false
+}
+
+// Report 用户对文章或评论的举报，每个用户对同一内容只能举报一次
+type Report struct {
+	ID         uint       `gorm:"primarykey" json:"id"`
+	TargetType string     `gorm:"uniqueIndex:idx_report_target_reporter;size:20;not null" json:"target_type"` // 举报对象类型
+	TargetID   uint       `gorm:"uniqueIndex:idx_report_target_reporter;not null" json:"target_id"`           // 举报对象ID
+	ReporterID uint       `gorm:"uniqueIndex:idx_report_target_reporter;not null;index" json:"reporter_id"`   // 举报人ID
+	Category   string     `gorm:"size:20;not null" json:"category"`                                           // 举报原因分类
+	Detail     string     `gorm:"type:text" json:"detail"`                                                    // 举报说明
+	Status     string     `gorm:"size:20;not null;default:open;index" json:"status"`                          // 处理状态
+	Action     string     `gorm:"size:20" json:"action,omitempty"`                                            // 处理方式
+	ResolverID *uint      `json:"resolver_id,omitempty"`                                                      // 处理人ID
+	ResolvedAt *time.Time `json:"resolved_at,omitempty"`                                                      // 处理时间
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+}
@@ -0,0 +1,36 @@
+package models
+
+import "gorm.io/gorm"
+
+type Series struct {
+	gorm.Model
+	Title       string       `gorm:"size:200;not null" json:"title"` // 系列标题
+	Description string       `gorm:"type:text" json:"description"`   // 系列简介
+	UserID      uint         `gorm:"index" json:"user_id"`           // 创建者ID
+	Parts       []SeriesPost `json:"-"`                              // 系列中的文章及顺序
+}
+
+// SeriesPost 系列与文章的关联，一篇文章最多属于一个系列
+type SeriesPost struct {
+	ID       uint `gorm:"primarykey" json:"-"`
+	SeriesID uint `gorm:"index;not null" json:"series_id"`     // 系列ID
+	PostID   uint `gorm:"uniqueIndex;not null" json:"post_id"` // 文章ID
+	Position int  `gorm:"not null" json:"position"`            // 在系列中的位置，从1开始
+}
+
+// SeriesLink 系列中相邻文章的链接
+type SeriesLink struct {
+	ID    uint   `json:"id"`
+	Title string `json:"title"`
+	URL   string `json:"url"`
+}
+
+// SeriesNav 文章所属系列的导航信息
+type SeriesNav struct {
+	ID       uint        `json:"id"`
+	Title    string      `json:"title"`
+	Position int         `json:"position"` // 当前文章是第几篇
+	Total    int         `json:"total"`    // 系列中可见的文章数
+	Prev     *SeriesLink `json:"prev"`     // 上一篇，没有时为 null
+	Next     *SeriesLink `json:"next"`     // 下一篇，没有时为 null
+}
@@ -0,0 +1,15 @@
+package models
+
+// 垃圾评论分类器的训练标签
+const (
+	SpamLabelSpam = "spam" // 垃圾评论
+	SpamLabelHam  = "ham"  // 正常评论
+)
+
+// SpamToken 朴素贝叶斯分类器的词频统计
+// 记录每个词出现在多少条垃圾评论和正常评论中
+type SpamToken struct {
+	Token string `gorm:"primaryKey;size:64"` // 词
+	Spam  int64  `gorm:"not null;default:0"` // 出现该词的垃圾评论数
+	Ham   int64  `gorm:"not null;default:0"` // 出现该词的正常评论数
+}
@@ -0,0 +1,9 @@
+package models
+
+import "gorm.io/gorm"
+
+type Tag struct {
+	gorm.Model
+	Name  string `gorm:"size:64;uniqueIndex;not null" json:"name"` // 标签名（已规范化）
+	Posts []Post `gorm:"many2many:post_tags;" json:"-"`            // 关联文章
+}
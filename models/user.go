@@ -1,12 +1,36 @@
 package models
 
-import "gorm.io/gorm"
+import (
+	"gorm.io/gorm"
+	"time"
+)
+
+// 用户角色
+const (
+	RoleUser  = "user"  // 普通用户
+	RoleAdmin = "admin" // 管理员
+)
 
 type User struct {
 	gorm.Model
-	Username string    `gorm:"unique;not null" form:"username" json:"username" binding:"required"` // 添加 form 和 json 标签
-	Password string    `gorm:"not null" form:"password" json:"password" binding:"required"`        // 添加 form 和 json 标签
-	Email    string    `gorm:"unique;not null" form:"email" json:"email" binding:"required,email"` // 添加 form 和 json 标签
-	Posts    []Post    `form:"-" json:"posts,omitempty"`                                           // 禁用 form 绑定
-	Comments []Comment `form:"-" json:"comments,omitempty"`                                        // 禁用 form 绑定
+	Username    string     `gorm:"unique;not null" form:"username" json:"username" binding:"required"` // 添加 form 和 json 标签
+	Password    string     `gorm:"not null" form:"password" json:"password" binding:"required"`        // 添加 form 和 json 标签
+	Email       string     `gorm:"unique;not null" form:"email" json:"email" binding:"required,email"` // 添加 form 和 json 标签
+	Role        string     `gorm:"size:20;not null;default:user" form:"-" json:"role"`                 // 用户角色，只能在数据库中提升为管理员
+	SuspendedAt *time.Time `form:"-" json:"suspended_at,omitempty"`                                    // 账号被停用的时间，停用后无法登录和调用需要认证的接口
+	Posts       []Post     `form:"-" json:"posts,omitempty"`                                           // 禁用 form 绑定
+	Comments    []Comment  `form:"-" json:"comments,omitempty"`                                        // 禁用 form 绑定
+}
+
+// UserSummary 对外展示的用户摘要，不包含密码等敏感字段
+type UserSummary struct {
+	ID       uint   `json:"id"`
+	Username string `json:"username"`
+	Avatar   string `gorm:"-" json:"avatar,omitempty"` // 头像地址，由邮箱生成
+	Guest    bool   `gorm:"-" json:"guest,omitempty"`  // 是否为游客，游客没有用户ID和头像
+}
+
+// TableName 指定 UserSummary 读取 users 表
+func (UserSummary) TableName() string {
+	return "users"
 }
@@ -0,0 +1,24 @@
+package models
+
+import (
+	"gorm.io/gorm"
+	"time"
+)
+
+// 评论投票的取值
+const (
+	VoteUp   = 1  // 赞成
+	VoteDown = -1 // 反对
+)
+
+// CommentVote 用户对评论的投票，每个用户对每条评论最多一票
+// 取消投票时软删除，再次投票时恢复原记录，以便根据更新/删除时间生成 Last-Modified
+type CommentVote struct {
+	ID        uint           `gorm:"primarykey" json:"-"`
+	CommentID uint           `gorm:"uniqueIndex:idx_comment_vote_user;not null" json:"comment_id"`    // 评论ID
+	UserID    uint           `gorm:"uniqueIndex:idx_comment_vote_user;not null;index" json:"user_id"` // 用户ID
+	Value     int            `gorm:"not null" json:"value"`                                           // 1 赞成，-1 反对
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
+}
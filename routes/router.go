@@ -3,7 +3,9 @@ package routes
 import (
 	"blog-system/controllers"
 	"blog-system/middleware"
+	"blog-system/storage"
 	"github.com/gin-gonic/gin"
+	"strings"
 )
 
 // InitRoutes 初始化应用路由
@@ -19,19 +21,130 @@ func InitRoutes(router *gin.Engine) {
 	// 文章相关路由
 	posts := router.Group("/posts")
 	{
-		posts.GET("", controllers.GetPosts)                                           // 获取文章列表
-		posts.GET("/:id", controllers.GetPost)                                        // 获取单篇文章
-		posts.Use(middleware.AuthMiddleware())                                        // 以下路由需要认证
-		posts.POST("", controllers.CreatePost)                                        // 创建文章
-		posts.PUT("/:id", middleware.AuthorizePostOwner(), controllers.UpdatePost)    // 更新文章
-		posts.DELETE("/:id", middleware.AuthorizePostOwner(), controllers.DeletePost) // 删除文章
+		posts.GET("", middleware.OptionalAuthMiddleware(), controllers.GetPosts)                                            // 获取文章列表
+		posts.GET("/:id", middleware.OptionalAuthMiddleware(), controllers.GetPost)                                         // 获取单篇文章（草稿和私密文章需协作者登录）
+		posts.POST("/:id/unlock", controllers.UnlockPost)                                                                   // 使用密码换取访问授权
+		posts.Use(middleware.AuthMiddleware())                                                                              // 以下路由需要认证
+		posts.POST("", controllers.CreatePost)                                                                              // 创建文章
+		posts.PUT("/:id", middleware.AuthorizePostEditor(), controllers.UpdatePost)                                         // 更新文章（所有者或合著者）
+		posts.DELETE("/:id", middleware.AuthorizePostOwner(), controllers.DeletePost)                                       // 删除文章（移入回收站）
+		posts.GET("/trash", controllers.GetTrash)                                                                           // 获取回收站中的文章
+		posts.POST("/:id/restore", middleware.AuthorizeTrashedPostOwner(), controllers.RestorePost)                         // 从回收站恢复文章
+		posts.DELETE("/:id/purge", middleware.AuthorizeTrashedPostOwner(), controllers.PurgePost)                           // 永久删除文章
+		posts.POST("/:id/transfer", middleware.AuthorizePostOwner(), controllers.TransferPost)                              // 转让文章
+		posts.GET("/:id/stats", middleware.AuthorizePostEditor(), controllers.GetPostStats)                                 // 获取文章统计（所有者或合著者）
+		posts.GET("/:id/collaborators", middleware.AuthorizePostCollaborator(), controllers.GetCollaborators)               // 获取协作者列表
+		posts.POST("/:id/collaborators", middleware.AuthorizePostOwner(), controllers.AddCollaborator)                      // 添加协作者
+		posts.PUT("/:id/collaborators/:user_id", middleware.AuthorizePostOwner(), controllers.UpdateCollaborator)           // 修改协作者角色
+		posts.DELETE("/:id/collaborators/:user_id", middleware.AuthorizePostCollaborator(), controllers.RemoveCollaborator) // 移除协作者或退出协作
+		posts.PUT("/:id/reactions/:type", controllers.AddReaction)                                                          // 添加反应
+		posts.DELETE("/:id/reactions/:type", controllers.RemoveReaction)                                                    // 取消反应
+	}
+
+	// 首页相关路由
+	home := router.Group("/home")
+	{
+		home.GET("", middleware.OptionalAuthMiddleware(), controllers.GetHome) // 获取首页（置顶、精选和最新文章）
+		home.Use(middleware.AuthMiddleware(), middleware.AuthorizeAdmin())     // 以下路由需要管理员权限
+		home.GET("/placements", controllers.GetHomePlacements)                 // 获取全部首页推荐
+		home.PUT("/:section/:post_id", controllers.SetHomePlacement)           // 置顶或精选文章
+		home.DELETE("/:section/:post_id", controllers.RemoveHomePlacement)     // 取消置顶或精选
+	}
+
+	// 系列相关路由
+	series := router.Group("/series")
+	{
+		series.GET("", controllers.GetSeriesList)                                      // 获取系列列表
+		series.GET("/:id", middleware.OptionalAuthMiddleware(), controllers.GetSeries) // 获取系列及其文章
+		series.Use(middleware.AuthMiddleware())                                        // 以下路由需要认证
+		series.POST("", controllers.CreateSeries)                                      // 创建系列
+		series.PUT("/:id", controllers.UpdateSeries)                                   // 修改系列信息
+		series.PUT("/:id/posts", controllers.ReorderSeries)                            // 设置系列文章及顺序
+		series.DELETE("/:id", controllers.DeleteSeries)                                // 删除系列
+	}
+
+	// 收藏相关路由
+	bookmarks := router.Group("/bookmarks")
+	{
+		bookmarks.Use(middleware.AuthMiddleware())                         // 全部需要认证
+		bookmarks.GET("", controllers.GetBookmarks)                        // 获取我的收藏
+		bookmarks.PUT("/:post_id", controllers.AddBookmark)                // 收藏文章或移动到其他收藏夹
+		bookmarks.DELETE("/:post_id", controllers.RemoveBookmark)          // 取消收藏
+		bookmarks.GET("/folders", controllers.GetBookmarkFolders)          // 获取收藏夹列表
+		bookmarks.POST("/folders", controllers.CreateBookmarkFolder)       // 创建收藏夹
+		bookmarks.PUT("/folders/:id", controllers.UpdateBookmarkFolder)    // 重命名收藏夹
+		bookmarks.DELETE("/folders/:id", controllers.DeleteBookmarkFolder) // 删除收藏夹
 	}
 
 	// 评论相关路由
 	comments := router.Group("/comments")
 	{
-		comments.GET("/post/:post_id", controllers.GetCommentsByPost) // 获取文章评论
-		comments.Use(middleware.AuthMiddleware())                     // 以下路由需要认证
-		comments.POST("", controllers.CreateComment)                  // 创建评论
+		comments.GET("/post/:post_id", middleware.OptionalAuthMiddleware(), controllers.GetCommentsByPost) // 获取文章评论
+		comments.GET("/challenge", controllers.GetCommentChallenge)                                        // 获取游客评论的工作量证明题目
+		comments.POST("", middleware.OptionalAuthMiddleware(), controllers.CreateComment)                  // 创建评论（文章允许时游客也可评论）
+		comments.Use(middleware.AuthMiddleware())                                                          // 以下路由需要认证
+		comments.GET("/moderation", controllers.GetModerationQueue)                                        // 获取审核队列（文章所有者或管理员）
+		comments.POST("/moderation", controllers.ModerateComments)                                         // 批量通过或拒绝评论
+		comments.PUT("/:id", middleware.AuthorizeCommentOwner(), controllers.UpdateComment)                // 编辑评论（评论作者）
+		comments.DELETE("/:id", middleware.AuthorizeCommentModerator(), controllers.DeleteComment)         // 删除评论（评论作者或文章所有者）
+		comments.PUT("/:id/vote", controllers.VoteComment)                                                 // 对评论投赞成或反对票
+		comments.DELETE("/:id/vote", controllers.UnvoteComment)                                            // 取消对评论的投票
+	}
+
+	// 用户相关路由
+	users := router.Group("/users")
+	{
+		users.Use(middleware.AuthMiddleware())                                                  // 全部需要认证
+		users.PUT("/:id/follow", controllers.FollowUser)                                        // 关注用户
+		users.DELETE("/:id/follow", controllers.UnfollowUser)                                   // 取消关注
+		users.DELETE("/:id/suspension", middleware.AuthorizeAdmin(), controllers.ReinstateUser) // 恢复被停用的账号（管理员）
+	}
+
+	// 提及我的内容
+	router.GET("/mentions", middleware.AuthMiddleware(), controllers.GetMentions)
+
+	// 举报相关路由
+	reports := router.Group("/reports")
+	{
+		reports.Use(middleware.AuthMiddleware())                                             // 全部需要认证
+		reports.POST("", controllers.CreateReport)                                           // 举报文章或评论
+		reports.GET("", middleware.AuthorizeAdmin(), controllers.GetReports)                 // 获取举报审核队列（管理员）
+		reports.POST("/:id/resolve", middleware.AuthorizeAdmin(), controllers.ResolveReport) // 处理举报（管理员）
+	}
+
+	// 媒体相关路由
+	media := router.Group("/media")
+	{
+		media.Use(middleware.AuthMiddleware())        // 全部需要认证
+		media.POST("", controllers.UploadMedia)       // 上传图片
+		media.GET("", controllers.GetMyMedia)         // 获取我上传的媒体
+		media.PUT("/:id", controllers.UpdateMedia)    // 修改媒体关联的文章
+		media.DELETE("/:id", controllers.DeleteMedia) // 删除媒体
+	}
+
+	// 本地存储时由应用直接提供上传文件的访问
+	if local, ok := storage.Default.(*storage.LocalStorage); ok && strings.HasPrefix(local.BaseURL, "/") {
+		router.Static(local.BaseURL, local.Root)
+	}
+
+	// 全文搜索
+	router.GET("/search", controllers.Search)
+
+	// 标签相关路由
+	tags := router.Group("/tags")
+	{
+		tags.GET("", controllers.GetTags)                                                                    // 获取标签列表及文章数
+		tags.POST("/merge", middleware.AuthMiddleware(), middleware.AuthorizeAdmin(), controllers.MergeTags) // 合并标签（管理员）
+	}
+
+	// 分类相关路由
+	categories := router.Group("/categories")
+	{
+		categories.GET("", controllers.GetCategories)                            // 获取分类树
+		categories.GET("/:id", controllers.GetCategory)                          // 获取单个分类
+		categories.Use(middleware.AuthMiddleware(), middleware.AuthorizeAdmin()) // 以下路由需要管理员权限
+		categories.POST("", controllers.CreateCategory)                          // 创建分类
+		categories.PUT("/:id", controllers.UpdateCategory)                       // 更新或移动分类
+		categories.DELETE("/:id", controllers.DeleteCategory)                    // 删除分类
 	}
 }
@@ -0,0 +1,156 @@
+package spam
+
+import (
+	"blog-system/models"
+	"fmt"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"math"
+	"strings"
+	"unicode"
+)
+
+// 分类器参数
+const (
+	bayesWeight    = 2.0 // 判定为垃圾评论的概率为 100% 时的得分
+	maxTokens      = 200 // 每条评论参与统计的最多词数
+	maxTokenLength = 64  // 词的最大长度
+	docsToken      = "*" // 记录训练样本数的保留词，分词结果中不会出现
+)
+
+// BayesFilter 朴素贝叶斯分类器，使用审核结果作为训练数据
+type BayesFilter struct {
+	DB      *gorm.DB // 数据库实例
+	MinDocs int64    // 垃圾评论和正常评论的样本数都达到该值后才参与评分
+}
+
+// Name 过滤器名称
+func (f *BayesFilter) Name() string { return "bayes" }
+
+// Check 计算评论为垃圾评论的概率，概率超过 50% 时按比例计分
+func (f *BayesFilter) Check(s *Submission) (float64, string, error) {
+	tokens := Tokenize(s.Content)
+	var rows []models.SpamToken
+	if err := f.DB.Where("token IN ?", append(tokens, docsToken)).Find(&rows).Error; err != nil {
+		return 0, "", err
+	}
+
+	var docs models.SpamToken
+	counts := make(map[string]models.SpamToken, len(rows))
+	for _, row := range rows {
+		if row.Token == docsToken {
+			docs = row
+		} else {
+			counts[row.Token] = row
+		}
+	}
+	if docs.Spam < f.MinDocs || docs.Ham < f.MinDocs || docs.Spam == 0 || docs.Ham == 0 {
+		return 0, "", nil
+	}
+
+	// 在对数空间中累加各词的似然比，使用拉普拉斯平滑避免零概率
+	logOdds := math.Log(float64(docs.Spam) / float64(docs.Ham))
+	for _, token := range tokens {
+		row, ok := counts[token]
+		if !ok {
+			continue
+		}
+		pSpam := (float64(row.Spam) + 1) / (float64(docs.Spam) + 2)
+		pHam := (float64(row.Ham) + 1) / (float64(docs.Ham) + 2)
+		logOdds += math.Log(pSpam / pHam)
+	}
+	probability := 1 / (1 + math.Exp(-logOdds))
+	if probability <= 0.5 {
+		return 0, "", nil
+	}
+	return bayesWeight * (probability - 0.5) * 2, fmt.Sprintf("spam probability %.2f", probability), nil
+}
+
+// Learn 用审核结果训练分类器
+// 同一条评论重新审核时先撤销之前的训练结果，避免重复计数
+// 参数: tx - 数据库实例, content - 评论内容, previous - 之前的训练标签（未训练时为空）, label - 新的训练标签
+func Learn(tx *gorm.DB, content, previous, label string) error {
+	if previous == label {
+		return nil
+	}
+	tokens := append(Tokenize(content), docsToken)
+	if previous != "" {
+		if err := adjustCounts(tx, tokens, previous, -1); err != nil {
+			return err
+		}
+	}
+	return adjustCounts(tx, tokens, label, 1)
+}
+
+// Forget 撤销一条评论的训练结果，评论内容被修改前调用
+// 参数: tx - 数据库实例, content - 训练时的评论内容, previous - 训练标签（未训练时为空）
+func Forget(tx *gorm.DB, content, previous string) error {
+	if previous == "" {
+		return nil
+	}
+	return adjustCounts(tx, append(Tokenize(content), docsToken), previous, -1)
+}
+
+// adjustCounts 调整一组词在某个标签下的计数
+func adjustCounts(tx *gorm.DB, tokens []string, label string, delta int64) error {
+	if label != models.SpamLabelSpam && label != models.SpamLabelHam {
+		return fmt.Errorf("unknown spam label %q", label)
+	}
+	for _, token := range tokens {
+		row := models.SpamToken{Token: token}
+		if delta > 0 {
+			if label == models.SpamLabelSpam {
+				row.Spam = delta
+			} else {
+				row.Ham = delta
+			}
+		}
+		err := tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "token"}},
+			DoUpdates: clause.Assignments(map[string]interface{}{label: gorm.Expr("spam_tokens."+label+" + ?", delta)}),
+		}).Create(&row).Error
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Tokenize 将评论内容切分为去重后的小写词
+// 字母和数字连续的部分作为一个词，汉字等没有空格分隔的文字按相邻两个字切分
+// 参数: content - 评论内容
+// 返回值: 词列表
+func Tokenize(content string) []string {
+	seen := make(map[string]bool)
+	var tokens []string
+	add := func(token string) {
+		if len(tokens) >= maxTokens || len(token) < 2 || len(token) > maxTokenLength || seen[token] {
+			return
+		}
+		seen[token] = true
+		tokens = append(tokens, token)
+	}
+
+	var word strings.Builder
+	var prevHan rune
+	for _, r := range strings.ToLower(content) {
+		if unicode.Is(unicode.Han, r) {
+			add(word.String())
+			word.Reset()
+			if prevHan != 0 {
+				add(string([]rune{prevHan, r}))
+			}
+			prevHan = r
+			continue
+		}
+		prevHan = 0
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			word.WriteRune(r)
+		} else {
+			add(word.String())
+			word.Reset()
+		}
+	}
+	add(word.String())
+	return tokens
+}
@@ -0,0 +1,139 @@
+package spam
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// 各过滤器命中时的得分
+const (
+	honeypotScore   = 5.0 // 填写了蜜罐字段，基本可以确定是机器人
+	timingScore     = 2.0 // 提交过快
+	linkScore       = 1.0 // 每超出一个链接
+	keywordScore    = 1.5 // 每命中一个关键词
+	domainScore     = 3.0 // 包含被禁止的域名
+	maxKeywordScore = 4.5 // 关键词得分上限
+)
+
+// linkPattern 匹配评论中的链接
+var linkPattern = regexp.MustCompile(`(?i)\b(?:https?://|www\.)[^\s<>()\[\]"']+`)
+
+// HoneypotFilter 蜜罐字段过滤器
+type HoneypotFilter struct{}
+
+// Name 过滤器名称
+func (f *HoneypotFilter) Name() string { return "honeypot" }
+
+// Check 蜜罐字段对正常用户不可见，有值时说明是自动提交
+func (f *HoneypotFilter) Check(s *Submission) (float64, string, error) {
+	if strings.TrimSpace(s.Honeypot) == "" {
+		return 0, "", nil
+	}
+	return honeypotScore, "honeypot field was filled in", nil
+}
+
+// TimingFilter 提交时间过滤器
+type TimingFilter struct {
+	MinDelay time.Duration // 从渲染表单到提交的最短时间
+}
+
+// Name 过滤器名称
+func (f *TimingFilter) Name() string { return "timing" }
+
+// Check 表单渲染后过快提交或渲染时间在未来时计分，客户端未提供渲染时间时不检查
+func (f *TimingFilter) Check(s *Submission) (float64, string, error) {
+	if s.RenderedAt.IsZero() || f.MinDelay <= 0 {
+		return 0, "", nil
+	}
+	elapsed := s.ReceivedAt.Sub(s.RenderedAt)
+	if elapsed < 0 {
+		return timingScore, "form rendered_at is in the future", nil
+	}
+	if elapsed < f.MinDelay {
+		return timingScore, fmt.Sprintf("submitted %.1fs after the form was rendered", elapsed.Seconds()), nil
+	}
+	return 0, "", nil
+}
+
+// LinkFilter 链接数量过滤器
+type LinkFilter struct {
+	MaxLinks int // 允许的最大链接数
+}
+
+// Name 过滤器名称
+func (f *LinkFilter) Name() string { return "links" }
+
+// Check 链接数超出上限时按超出数量计分
+func (f *LinkFilter) Check(s *Submission) (float64, string, error) {
+	count := len(linkPattern.FindAllString(s.Content, -1))
+	if count <= f.MaxLinks {
+		return 0, "", nil
+	}
+	return float64(count-f.MaxLinks) * linkScore, fmt.Sprintf("%d links (limit %d)", count, f.MaxLinks), nil
+}
+
+// KeywordFilter 关键词过滤器
+type KeywordFilter struct {
+	Keywords []string // 小写的关键词
+}
+
+// Name 过滤器名称
+func (f *KeywordFilter) Name() string { return "keywords" }
+
+// Check 按命中的关键词数量计分，不区分大小写
+func (f *KeywordFilter) Check(s *Submission) (float64, string, error) {
+	content := strings.ToLower(s.Content)
+	var matched []string
+	for _, keyword := range f.Keywords {
+		if strings.Contains(content, keyword) {
+			matched = append(matched, keyword)
+		}
+	}
+	if len(matched) == 0 {
+		return 0, "", nil
+	}
+	score := float64(len(matched)) * keywordScore
+	if score > maxKeywordScore {
+		score = maxKeywordScore
+	}
+	return score, "blocked keywords: " + strings.Join(matched, ", "), nil
+}
+
+// DomainFilter 域名黑名单过滤器
+type DomainFilter struct {
+	Domains []string // 小写的域名，同时匹配其子域名
+}
+
+// Name 过滤器名称
+func (f *DomainFilter) Name() string { return "domains" }
+
+// Check 链接指向被禁止的域名或其子域名时计分
+func (f *DomainFilter) Check(s *Submission) (float64, string, error) {
+	if len(f.Domains) == 0 {
+		return 0, "", nil
+	}
+	for _, link := range linkPattern.FindAllString(s.Content, -1) {
+		host := linkHost(link)
+		for _, domain := range f.Domains {
+			if host == domain || strings.HasSuffix(host, "."+domain) {
+				return domainScore, "links to blocked domain " + domain, nil
+			}
+		}
+	}
+	return 0, "", nil
+}
+
+// linkHost 提取链接的小写主机名
+func linkHost(link string) string {
+	if !strings.Contains(link, "://") {
+		link = "http://" + link
+	}
+	parsed, err := url.Parse(link)
+	if err != nil {
+		return ""
+	}
+	return strings.ToLower(parsed.Hostname())
+}
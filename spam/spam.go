@@ -0,0 +1,103 @@
+package spam
+
+import (
+	"blog-system/config"
+	"blog-system/database"
+	"log"
+	"strings"
+	"time"
+)
+
+// Submission 待检查的评论
+type Submission struct {
+	Content    string    // 评论内容
+	UserID     uint      // 评论者ID
+	PostID     uint      // 文章ID
+	Honeypot   string    // 蜜罐字段的值，正常用户看不到也不会填写
+	RenderedAt time.Time // 评论表单的渲染时间，客户端未提供时为零值
+	ReceivedAt time.Time // 服务器收到评论的时间
+}
+
+// Filter 垃圾评论过滤器
+// Check 返回非负得分，得分越高越可能是垃圾评论；得分大于0时应同时给出原因
+type Filter interface {
+	Name() string
+	Check(s *Submission) (score float64, reason string, err error)
+}
+
+// Result 单个过滤器的检查结果
+type Result struct {
+	Filter string  `json:"filter"`
+	Score  float64 `json:"score"`
+	Reason string  `json:"reason"`
+}
+
+// Verdict 过滤链的检查结果
+type Verdict struct {
+	Score   float64  // 各过滤器得分之和
+	Results []Result // 得分大于0的过滤器结果
+}
+
+// Reasons 返回命中原因的文字描述
+func (v Verdict) Reasons() string {
+	reasons := make([]string, len(v.Results))
+	for i, result := range v.Results {
+		reasons[i] = result.Filter + ": " + result.Reason
+	}
+	return strings.Join(reasons, "; ")
+}
+
+// Pipeline 依次执行的过滤器链
+type Pipeline struct {
+	Filters []Filter
+}
+
+// Check 依次执行全部过滤器并累加得分
+// 单个过滤器出错时记录日志并跳过，不影响评论提交
+// 参数: s - 待检查的评论
+// 返回值: 检查结果
+func (p *Pipeline) Check(s *Submission) Verdict {
+	var verdict Verdict
+	if p == nil {
+		return verdict
+	}
+	for _, filter := range p.Filters {
+		score, reason, err := filter.Check(s)
+		if err != nil {
+			log.Printf("垃圾评论过滤器 %s 执行失败: %v", filter.Name(), err)
+			continue
+		}
+		if score > 0 {
+			verdict.Score += score
+			verdict.Results = append(verdict.Results, Result{Filter: filter.Name(), Score: score, Reason: reason})
+		}
+	}
+	return verdict
+}
+
+// Default 全局过滤器链
+var Default *Pipeline
+
+// InitFilters 根据配置初始化过滤器链
+func InitFilters() {
+	cfg := config.LoadConfig()
+	Default = &Pipeline{Filters: []Filter{
+		&HoneypotFilter{},
+		&TimingFilter{MinDelay: cfg.SpamMinSubmitTime},
+		&LinkFilter{MaxLinks: cfg.SpamMaxLinks},
+		&KeywordFilter{Keywords: splitList(cfg.SpamKeywords)},
+		&DomainFilter{Domains: splitList(cfg.SpamDomains)},
+		&BayesFilter{DB: database.DB, MinDocs: cfg.SpamBayesMinDocs},
+	}}
+}
+
+// splitList 解析逗号分隔的配置项，去除空白并统一为小写
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.ToLower(strings.TrimSpace(item)); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
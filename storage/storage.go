@@ -0,0 +1,42 @@
+package storage
+
+import (
+	"blog-system/config"
+	"context"
+	"fmt"
+	"io"
+	"log"
+)
+
+// Storage 文件存储后端接口
+// key 为不含前导斜杠的相对路径，例如 "media/2026/10/abc.jpg"
+type Storage interface {
+	// Save 保存文件，已存在时覆盖
+	Save(ctx context.Context, key string, r io.Reader, contentType string) error
+	// Delete 删除文件，文件不存在时不返回错误
+	Delete(ctx context.Context, key string) error
+	// URL 返回文件的访问地址
+	URL(key string) string
+}
+
+// Default 全局存储后端
+var Default Storage
+
+// InitStorage 根据配置初始化存储后端
+func InitStorage() error {
+	cfg := config.LoadConfig()
+
+	switch cfg.StorageDriver {
+	case "local":
+		local, err := NewLocalStorage(cfg.UploadDir, cfg.UploadBaseURL)
+		if err != nil {
+			return err
+		}
+		Default = local
+	default:
+		return fmt.Errorf("不支持的存储驱动: %s", cfg.StorageDriver)
+	}
+
+	log.Printf("使用 %s 存储后端", cfg.StorageDriver)
+	return nil
+}
@@ -0,0 +1,24 @@
+package utils
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"strings"
+)
+
+// avatarBaseURL Gravatar 兼容的头像服务地址，启动时由配置设置
+var avatarBaseURL = "https://www.gravatar.com/avatar"
+
+// SetAvatarBaseURL 设置头像服务地址
+// 参数: baseURL - 头像服务地址（如 AVATAR_BASE_URL 配置项）
+func SetAvatarBaseURL(baseURL string) {
+	avatarBaseURL = strings.TrimRight(baseURL, "/")
+}
+
+// AvatarURL 根据邮箱生成 Gravatar 兼容的头像地址，未设置头像时显示默认图案
+// 参数: email - 用户邮箱
+// 返回值: 头像地址
+func AvatarURL(email string) string {
+	sum := md5.Sum([]byte(strings.ToLower(strings.TrimSpace(email))))
+	return avatarBaseURL + "/" + hex.EncodeToString(sum[:]) + "?d=identicon"
+}
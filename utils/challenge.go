@@ -0,0 +1,80 @@
+package utils
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"github.com/golang-jwt/jwt/v4"
+	"math/bits"
+	"time"
+)
+
+// challengeAudience 游客评论工作量证明令牌的受众，与登录令牌区分
+const challengeAudience = "comment-challenge"
+
+// ChallengeClaims 游客评论的工作量证明题目
+type ChallengeClaims struct {
+	jwt.RegisteredClaims
+	PostID     uint // 文章ID
+	Difficulty int  // 要求的哈希前导零比特数
+}
+
+// GenerateChallenge 生成游客评论的工作量证明题目
+// 客户端需要找到 nonce，使 sha256(题目 + ":" + nonce) 至少有 difficulty 个前导零比特
+// 参数: postID - 文章ID, difficulty - 难度, secret - 签名密钥, ttl - 有效期
+// 返回值: 题目, 过期时间, 错误信息
+func GenerateChallenge(postID uint, difficulty int, secret string, ttl time.Duration) (string, time.Time, error) {
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		return "", time.Time{}, err
+	}
+
+	expiresAt := time.Now().Add(ttl)
+	claims := ChallengeClaims{
+		PostID:     postID,
+		Difficulty: difficulty,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        hex.EncodeToString(salt),
+			Audience:  jwt.ClaimStrings{challengeAudience},
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+		},
+	}
+
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(secret))
+	return token, expiresAt, err
+}
+
+// VerifyChallenge 验证工作量证明的答案
+// 参数: tokenString - 题目, nonce - 答案, postID - 文章ID, secret - 签名密钥
+// 返回值: 题目声明（用于防止重复使用）, 错误信息
+func VerifyChallenge(tokenString, nonce string, postID uint, secret string) (*ChallengeClaims, error) {
+	claims := &ChallengeClaims{}
+	token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+		}
+		return []byte(secret), nil
+	})
+	if err != nil || !token.Valid || !claims.VerifyAudience(challengeAudience, true) || claims.PostID != postID {
+		return nil, fmt.Errorf("无效或已过期的验证题目")
+	}
+
+	sum := sha256.Sum256([]byte(tokenString + ":" + nonce))
+	if leadingZeroBits(sum[:]) < claims.Difficulty {
+		return nil, fmt.Errorf("验证答案不正确")
+	}
+	return claims, nil
+}
+
+// leadingZeroBits 统计字节序列的前导零比特数
+func leadingZeroBits(data []byte) int {
+	count := 0
+	for _, b := range data {
+		if b != 0 {
+			return count + bits.LeadingZeros8(b)
+		}
+		count += 8
+	}
+	return count
+}
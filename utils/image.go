@@ -0,0 +1,325 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"golang.org/x/image/draw"
+	"image"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"net/http"
+)
+
+// 图片处理参数
+const (
+	maxImagePixels = 40_000_000 // 最大像素数，防止解压炸弹
+	thumbnailSize  = 320        // 缩略图最长边
+	jpegQuality    = 90         // 重新编码 JPEG 的质量
+)
+
+// ErrUnsupportedImage 不支持的图片格式
+var ErrUnsupportedImage = errors.New("unsupported image type")
+
+// ProcessedImage 处理后的图片
+type ProcessedImage struct {
+	MimeType      string // 嗅探得到的 MIME 类型
+	Ext           string // 文件扩展名
+	Data          []byte // 去除元数据后的图片
+	Width         int    // 宽度
+	Height        int    // 高度
+	Thumbnail     []byte // 缩略图
+	ThumbnailMime string // 缩略图 MIME 类型
+}
+
+// ProcessImage 嗅探图片类型、去除 EXIF 等元数据并生成缩略图
+// 图片会被重新编码，因此只保留像素数据；JPEG 会先按 EXIF 方向信息摆正
+// 参数: data - 上传的原始文件内容
+// 返回值: 处理结果, 错误信息
+func ProcessImage(data []byte) (*ProcessedImage, error) {
+	// 不信任客户端声明的 Content-Type，按文件内容嗅探
+	mimeType := http.DetectContentType(data)
+	switch mimeType {
+	case "image/jpeg", "image/png", "image/gif":
+	default:
+		return nil, ErrUnsupportedImage
+	}
+
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	if cfg.Width*cfg.Height > maxImagePixels {
+		return nil, errors.New("image dimensions too large")
+	}
+
+	result := &ProcessedImage{MimeType: mimeType}
+	var img image.Image
+	var out bytes.Buffer
+
+	switch mimeType {
+	case "image/jpeg":
+		if img, err = jpeg.Decode(bytes.NewReader(data)); err != nil {
+			return nil, err
+		}
+		img = applyOrientation(img, jpegOrientation(data))
+		err = jpeg.Encode(&out, img, &jpeg.Options{Quality: jpegQuality})
+		result.Ext = ".jpg"
+	case "image/png":
+		if img, err = png.Decode(bytes.NewReader(data)); err != nil {
+			return nil, err
+		}
+		err = png.Encode(&out, img)
+		result.Ext = ".png"
+	case "image/gif":
+		// 逻辑屏幕尺寸不限制帧数，解码前先按各帧尺寸累计像素数，防止大量高压缩比的帧耗尽内存
+		var pixels int
+		if pixels, err = gifFramePixels(data); err != nil {
+			return nil, err
+		}
+		if pixels > maxImagePixels {
+			return nil, errors.New("image dimensions too large")
+		}
+		// 保留动画帧，重新编码会丢弃注释等扩展块
+		var anim *gif.GIF
+		if anim, err = gif.DecodeAll(bytes.NewReader(data)); err != nil {
+			return nil, err
+		}
+		img = anim.Image[0]
+		err = gif.EncodeAll(&out, anim)
+		result.Ext = ".gif"
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	result.Data = out.Bytes()
+	if mimeType == "image/gif" {
+		// 首帧可能只覆盖画布的一部分，尺寸以逻辑屏幕为准
+		result.Width, result.Height = cfg.Width, cfg.Height
+	} else {
+		bounds := img.Bounds()
+		result.Width, result.Height = bounds.Dx(), bounds.Dy()
+	}
+
+	// JPEG 缩略图仍用 JPEG，其余格式用 PNG 以保留透明度
+	var thumb bytes.Buffer
+	if mimeType == "image/jpeg" {
+		err = jpeg.Encode(&thumb, thumbnail(img), &jpeg.Options{Quality: 80})
+		result.ThumbnailMime = "image/jpeg"
+	} else {
+		err = png.Encode(&thumb, thumbnail(img))
+		result.ThumbnailMime = "image/png"
+	}
+	if err != nil {
+		return nil, err
+	}
+	result.Thumbnail = thumb.Bytes()
+
+	return result, nil
+}
+
+// thumbnail 按比例缩放到最长边不超过 thumbnailSize
+func thumbnail(img image.Image) image.Image {
+	bounds := img.Bounds()
+	w, h := bounds.Dx(), bounds.Dy()
+	if w <= thumbnailSize && h <= thumbnailSize {
+		return img
+	}
+
+	if w >= h {
+		h = max(1, h*thumbnailSize/w)
+		w = thumbnailSize
+	} else {
+		w = max(1, w*thumbnailSize/h)
+		h = thumbnailSize
+	}
+
+	dst := image.NewNRGBA(image.Rect(0, 0, w, h))
+	draw.CatmullRom.Scale(dst, dst.Bounds(), img, bounds, draw.Src, nil)
+	return dst
+}
+
+// jpegOrientation 读取 JPEG 中 EXIF 的方向标记
+// 参数: data - JPEG 文件内容
+// 返回值: 方向值 1-8，未找到时返回 1
+func jpegOrientation(data []byte) int {
+	// 逐个遍历 JPEG 段，查找 APP1 中的 Exif 数据
+	for i := 2; i+4 <= len(data); {
+		if data[i] != 0xFF {
+			return 1
+		}
+		marker := data[i+1]
+		if marker == 0xD8 || (marker >= 0xD0 && marker <= 0xD7) {
+			i += 2
+			continue
+		}
+		// 图像数据开始后不会再有 EXIF
+		if marker == 0xDA || marker == 0xD9 {
+			return 1
+		}
+
+		length := int(binary.BigEndian.Uint16(data[i+2:]))
+		if length < 2 || i+2+length > len(data) {
+			return 1
+		}
+		segment := data[i+4 : i+2+length]
+		if marker == 0xE1 && len(segment) > 6 && string(segment[:6]) == "Exif\x00\x00" {
+			return tiffOrientation(segment[6:])
+		}
+		i += 2 + length
+	}
+	return 1
+}
+
+// gifFramePixels 不解码像素，遍历 GIF 的数据块累计所有帧的像素数
+// 参数: data - GIF 文件内容
+// 返回值: 各帧宽高乘积之和, 错误信息
+func gifFramePixels(data []byte) (int, error) {
+	errMalformed := errors.New("gif: malformed data")
+	// 文件头 6 字节 + 逻辑屏幕描述符 7 字节，之后可能是全局颜色表
+	if len(data) < 13 {
+		return 0, errMalformed
+	}
+	i := 13
+	if data[10]&0x80 != 0 {
+		i += 3 << (data[10]&0x07 + 1)
+	}
+
+	// skipSubBlocks 跳过以长度为 0 的子块结尾的数据子块序列
+	skipSubBlocks := func(i int) (int, error) {
+		for i < len(data) {
+			size := int(data[i])
+			i++
+			if size == 0 {
+				return i, nil
+			}
+			i += size
+		}
+		return 0, errMalformed
+	}
+
+	pixels := 0
+	for i < len(data) {
+		switch data[i] {
+		case 0x21: // 扩展块：标签 + 数据子块
+			if i+2 > len(data) {
+				return 0, errMalformed
+			}
+			next, err := skipSubBlocks(i + 2)
+			if err != nil {
+				return 0, err
+			}
+			i = next
+		case 0x2C: // 图像描述符：位置和尺寸 8 字节 + 标志，之后可能是局部颜色表和 LZW 最小码长
+			if i+10 > len(data) {
+				return 0, errMalformed
+			}
+			width := int(binary.LittleEndian.Uint16(data[i+5:]))
+			height := int(binary.LittleEndian.Uint16(data[i+7:]))
+			pixels += width * height
+			if pixels > maxImagePixels {
+				return pixels, nil
+			}
+			flags := data[i+9]
+			i += 10
+			if flags&0x80 != 0 {
+				i += 3 << (flags&0x07 + 1)
+			}
+			next, err := skipSubBlocks(i + 1)
+			if err != nil {
+				return 0, err
+			}
+			i = next
+		case 0x3B: // 结束标记
+			return pixels, nil
+		default:
+			return 0, errMalformed
+		}
+	}
+	return pixels, nil
+}
+
+// tiffOrientation 在 TIFF 结构的 IFD0 中查找方向标记 (0x0112)
+func tiffOrientation(tiff []byte) int {
+	if len(tiff) < 8 {
+		return 1
+	}
+
+	var order binary.ByteOrder
+	switch string(tiff[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return 1
+	}
+
+	offset := int(order.Uint32(tiff[4:]))
+	if offset < 8 || offset+2 > len(tiff) {
+		return 1
+	}
+	count := int(order.Uint16(tiff[offset:]))
+	for i := 0; i < count; i++ {
+		entry := offset + 2 + i*12
+		if entry+12 > len(tiff) {
+			return 1
+		}
+		if order.Uint16(tiff[entry:]) == 0x0112 {
+			value := int(order.Uint16(tiff[entry+8:]))
+			if value >= 1 && value <= 8 {
+				return value
+			}
+			return 1
+		}
+	}
+	return 1
+}
+
+// applyOrientation 根据 EXIF 方向值翻转或旋转图片
+// 参数: img - 原图, orientation - EXIF 方向值 1-8
+// 返回值: 摆正后的图片
+func applyOrientation(img image.Image, orientation int) image.Image {
+	if orientation <= 1 || orientation > 8 {
+		return img
+	}
+
+	bounds := img.Bounds()
+	src := image.NewNRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	draw.Draw(src, src.Bounds(), img, bounds.Min, draw.Src)
+
+	w, h := bounds.Dx(), bounds.Dy()
+	dw, dh := w, h
+	if orientation >= 5 {
+		dw, dh = h, w
+	}
+	dst := image.NewNRGBA(image.Rect(0, 0, dw, dh))
+
+	for y := 0; y < dh; y++ {
+		for x := 0; x < dw; x++ {
+			var sx, sy int
+			switch orientation {
+			case 2: // 水平翻转
+				sx, sy = w-1-x, y
+			case 3: // 旋转180度
+				sx, sy = w-1-x, h-1-y
+			case 4: // 垂直翻转
+				sx, sy = x, h-1-y
+			case 5: // 沿主对角线翻转
+				sx, sy = y, x
+			case 6: // 顺时针旋转90度
+				sx, sy = y, h-1-x
+			case 7: // 沿副对角线翻转
+				sx, sy = w-1-y, h-1-x
+			case 8: // 逆时针旋转90度
+				sx, sy = w-1-y, x
+			}
+			si := src.PixOffset(sx, sy)
+			di := dst.PixOffset(x, y)
+			copy(dst.Pix[di:di+4], src.Pix[si:si+4])
+		}
+	}
+	return dst
+}
@@ -1,6 +1,8 @@
 package utils
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"github.com/golang-jwt/jwt/v4"
 	"time"
@@ -57,3 +59,54 @@ func ParseToken(tokenString, secret string) (*CustomClaims, error) {
 
 	return nil, fmt.Errorf("无效的令牌声明")
 }
+
+// postAccessAudience 文章访问授权令牌的受众，与登录令牌区分
+const postAccessAudience = "post-access"
+
+// PostAccessClaims 受密码保护文章的访问授权声明
+type PostAccessClaims struct {
+	jwt.RegisteredClaims
+	PostID uint   // 文章ID
+	Key    string // 文章密码哈希的指纹，修改密码后旧授权失效
+}
+
+// GeneratePostAccessToken 生成受密码保护文章的短期访问授权
+// 参数: postID - 文章ID, passwordHash - 文章密码哈希, secret - 签名密钥, ttl - 有效期
+// 返回值: 令牌, 过期时间, 错误信息
+func GeneratePostAccessToken(postID uint, passwordHash, secret string, ttl time.Duration) (string, time.Time, error) {
+	expiresAt := time.Now().Add(ttl)
+	claims := PostAccessClaims{
+		PostID: postID,
+		Key:    passwordFingerprint(passwordHash),
+		RegisteredClaims: jwt.RegisteredClaims{
+			Audience:  jwt.ClaimStrings{postAccessAudience},
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+		},
+	}
+
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(secret))
+	return token, expiresAt, err
+}
+
+// VerifyPostAccessToken 验证访问授权是否适用于指定文章
+// 参数: tokenString - 令牌, postID - 文章ID, passwordHash - 文章当前密码哈希, secret - 签名密钥
+// 返回值: 是否有效
+func VerifyPostAccessToken(tokenString string, postID uint, passwordHash, secret string) bool {
+	claims := &PostAccessClaims{}
+	token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+		}
+		return []byte(secret), nil
+	})
+	if err != nil || !token.Valid || !claims.VerifyAudience(postAccessAudience, true) {
+		return false
+	}
+	return claims.PostID == postID && claims.Key == passwordFingerprint(passwordHash)
+}
+
+// passwordFingerprint 生成密码哈希的短指纹，避免在令牌中暴露哈希本身
+func passwordFingerprint(passwordHash string) string {
+	sum := sha256.Sum256([]byte(passwordHash))
+	return hex.EncodeToString(sum[:8])
+}
@@ -0,0 +1,154 @@
+package utils
+
+import (
+	"blog-system/models"
+	"bytes"
+	"fmt"
+	"github.com/microcosm-cc/bluemonday"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/extension"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/renderer/html"
+	"github.com/yuin/goldmark/text"
+	stdhtml "html"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// markdown 渲染器：启用 GFM 扩展并为标题自动生成锚点
+// 原始 HTML 先原样输出，再统一交给 sanitizer 按白名单过滤
+var markdown = goldmark.New(
+	goldmark.WithExtensions(extension.GFM),
+	goldmark.WithParserOptions(parser.WithAutoHeadingID()),
+	goldmark.WithRendererOptions(html.WithUnsafe()),
+)
+
+// sanitizer HTML 白名单策略
+var sanitizer = newSanitizer()
+
+// newSanitizer 创建 HTML 白名单策略
+// 在用户内容策略的基础上允许代码高亮类名、标题锚点和任务列表复选框，链接统一加 nofollow
+func newSanitizer() *bluemonday.Policy {
+	policy := bluemonday.UGCPolicy()
+	policy.AllowAttrs("class").Matching(regexp.MustCompile(`^language-[\w+#-]+$`)).OnElements("code")
+	policy.AllowAttrs("id").Matching(regexp.MustCompile(`^[\p{L}\p{N}_-]+$`)).OnElements("h1", "h2", "h3", "h4", "h5", "h6")
+	policy.AllowAttrs("type").Matching(regexp.MustCompile(`^checkbox$`)).OnElements("input")
+	policy.AllowAttrs("checked", "disabled").OnElements("input")
+	policy.AllowURLSchemes("http", "https", "mailto")
+	policy.RequireNoFollowOnLinks(true)
+	policy.RequireNoReferrerOnLinks(true)
+	policy.AddTargetBlankToFullyQualifiedLinks(true)
+	return policy
+}
+
+// RenderMarkdown 将 Markdown 渲染为净化后的 HTML 并生成目录
+// 参数: source - Markdown 原文
+// 返回值: HTML, 目录, 错误信息
+func RenderMarkdown(source string) (string, models.TOC, error) {
+	src := []byte(source)
+	ctx := parser.NewContext(parser.WithIDs(&headingIDs{values: make(map[string]bool)}))
+	doc := markdown.Parser().Parse(text.NewReader(src), parser.WithContext(ctx))
+
+	var buf bytes.Buffer
+	if err := markdown.Renderer().Render(&buf, src, doc); err != nil {
+		return "", nil, err
+	}
+
+	return sanitizer.Sanitize(buf.String()), buildTOC(doc, src), nil
+}
+
+// stripTags 去除全部 HTML 标签的策略
+var stripTags = bluemonday.StrictPolicy()
+
+// Teaser 从渲染后的 HTML 中提取纯文本摘要
+// 参数: contentHTML - 渲染后的 HTML, width - 最大字符数
+// 返回值: 纯文本摘要，被截断时以省略号结尾
+func Teaser(contentHTML string, width int) string {
+	plain := stdhtml.UnescapeString(stripTags.Sanitize(contentHTML))
+	runes := []rune(strings.Join(strings.Fields(plain), " "))
+	if len(runes) <= width {
+		return string(runes)
+	}
+	return string(runes[:width]) + "…"
+}
+
+// headingIDs 标题锚点生成器
+// goldmark 默认只保留 ASCII 字符，这里保留中文等 Unicode 字母和数字
+type headingIDs struct {
+	values map[string]bool
+}
+
+// Generate 根据标题文本生成唯一锚点
+func (s *headingIDs) Generate(value []byte, kind ast.NodeKind) []byte {
+	var sb strings.Builder
+	for _, r := range strings.ToLower(strings.TrimSpace(string(value))) {
+		switch {
+		case unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_' || r == '-':
+			sb.WriteRune(r)
+		case unicode.IsSpace(r):
+			sb.WriteByte('-')
+		}
+	}
+
+	base := sb.String()
+	if base == "" {
+		base = "heading"
+	}
+	id := base
+	for i := 1; s.values[id]; i++ {
+		id = fmt.Sprintf("%s-%d", base, i)
+	}
+	s.values[id] = true
+	return []byte(id)
+}
+
+// Put 记录已存在的锚点
+func (s *headingIDs) Put(value []byte) {
+	s.values[string(value)] = true
+}
+
+// buildTOC 遍历语法树收集标题生成目录
+// 参数: doc - Markdown 语法树, source - Markdown 原文
+// 返回值: 目录
+func buildTOC(doc ast.Node, source []byte) models.TOC {
+	toc := models.TOC{}
+	_ = ast.Walk(doc, func(node ast.Node, entering bool) (ast.WalkStatus, error) {
+		heading, ok := node.(*ast.Heading)
+		if !entering || !ok {
+			return ast.WalkContinue, nil
+		}
+
+		entry := models.TOCEntry{Level: heading.Level, Text: strings.TrimSpace(nodeText(heading, source))}
+		if id, ok := heading.AttributeString("id"); ok {
+			if value, ok := id.([]byte); ok {
+				entry.ID = string(value)
+			}
+		}
+		toc = append(toc, entry)
+		return ast.WalkSkipChildren, nil
+	})
+	return toc
+}
+
+// nodeText 提取节点下的纯文本
+// 参数: node - 语法树节点, source - Markdown 原文
+// 返回值: 纯文本
+func nodeText(node ast.Node, source []byte) string {
+	var sb strings.Builder
+	for child := node.FirstChild(); child != nil; child = child.NextSibling() {
+		switch n := child.(type) {
+		case *ast.Text:
+			sb.Write(n.Segment.Value(source))
+			if n.SoftLineBreak() {
+				sb.WriteByte(' ')
+			}
+		case *ast.String:
+			sb.Write(n.Value)
+		default:
+			sb.WriteString(nodeText(child, source))
+		}
+	}
+	return sb.String()
+}
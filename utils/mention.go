@@ -0,0 +1,93 @@
+package utils
+
+import (
+	"strings"
+	"unicode"
+)
+
+// maxMentionLength 提及的用户名最大长度（字符数）
+const maxMentionLength = 64
+
+// MentionSpan 文本中的一处 @提及
+type MentionSpan struct {
+	Username string // 被提及的用户名（不含 @）
+	Start    int    // @ 所在位置（按 Unicode 字符计算）
+	End      int    // 用户名结束位置（不含）
+}
+
+// ParseMentions 解析文本中的 @用户名
+// 用户名由字母、数字、下划线、点和连字符组成，末尾的点和连字符视为标点；
+// @ 前紧跟字母数字等字符时（如邮箱地址）不视为提及，Markdown 代码块和行内代码中的内容会被忽略
+// 参数: content - 文本（Markdown）
+// 返回值: 按出现顺序排列的提及
+func ParseMentions(content string) []MentionSpan {
+	var spans []MentionSpan
+	offset := 0
+	fenced := ""
+	for _, line := range strings.SplitAfter(content, "\n") {
+		runes := []rune(line)
+		if fence := codeFence(line); fence != "" {
+			if fenced == "" {
+				fenced = fence
+			} else if strings.HasPrefix(fence, fenced) {
+				fenced = ""
+			}
+			offset += len(runes)
+			continue
+		}
+		if fenced == "" {
+			spans = append(spans, lineMentions(runes, offset)...)
+		}
+		offset += len(runes)
+	}
+	return spans
+}
+
+// codeFence 判断行是否为代码块的围栏，是则返回围栏字符串
+func codeFence(line string) string {
+	trimmed := strings.TrimLeft(line, " ")
+	if len(line)-len(trimmed) > 3 {
+		return ""
+	}
+	for _, marker := range []string{"`", "~"} {
+		if run := len(trimmed) - len(strings.TrimLeft(trimmed, marker)); run >= 3 {
+			return trimmed[:run]
+		}
+	}
+	return ""
+}
+
+// lineMentions 解析一行中的提及，跳过行内代码
+func lineMentions(runes []rune, offset int) []MentionSpan {
+	var spans []MentionSpan
+	inCode := false
+	for i := 0; i < len(runes); i++ {
+		switch {
+		case runes[i] == '`':
+			inCode = !inCode
+		case runes[i] == '@' && !inCode && (i == 0 || !isMentionBoundary(runes[i-1])):
+			end := i + 1
+			for end < len(runes) && end-i-1 < maxMentionLength && isMentionRune(runes[end]) {
+				end++
+			}
+			for end > i+1 && (runes[end-1] == '.' || runes[end-1] == '-') {
+				end--
+			}
+			if end > i+1 {
+				spans = append(spans, MentionSpan{Username: string(runes[i+1 : end]), Start: offset + i, End: offset + end})
+				i = end - 1
+			}
+		}
+	}
+	return spans
+}
+
+// isMentionRune 判断字符能否出现在用户名中
+func isMentionRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_' || r == '.' || r == '-'
+}
+
+// isMentionBoundary 判断 @ 前的字符是否使其不构成提及
+func isMentionBoundary(r rune) bool {
+	return isMentionRune(r) || r == '@' || r == '/'
+}
@@ -0,0 +1,22 @@
+package utils
+
+import "math"
+
+// wilsonZ Wilson 区间使用的正态分位数，对应 95% 置信度
+const wilsonZ = 1.96
+
+// WilsonLowerBound 计算赞成比例的 Wilson 得分区间下界
+// 票数少时下界偏低，避免只有一两张赞成票的内容排在大量投票且好评率高的内容之前
+// 参数: up - 赞成票数, down - 反对票数
+// 返回值: 0 到 1 之间的得分，没有投票时为 0
+func WilsonLowerBound(up, down int64) float64 {
+	n := float64(up + down)
+	if n <= 0 {
+		return 0
+	}
+	p := float64(up) / n
+	z2 := wilsonZ * wilsonZ
+	lower := (p + z2/(2*n) - wilsonZ*math.Sqrt((p*(1-p)+z2/(4*n))/n)) / (1 + z2/n)
+	// 全部为反对票时理论值为 0，浮点误差可能产生极小的正数
+	return math.Max(0, math.Round(lower*1e9)/1e9)
+}
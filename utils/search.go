@@ -0,0 +1,142 @@
+package utils
+
+import (
+	"html"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// isCJK 判断是否为中日韩字符
+func isCJK(r rune) bool {
+	return unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Hangul)
+}
+
+// SegmentSearchText 为全文索引切分文本
+// 中日韩字符之间没有空格，分词器会把整段视为一个词，这里在每个字符两侧插入空格使其成为独立的词，
+// 查询时再以短语匹配相邻字符，从而支持任意长度的中文子串检索
+// 参数: text - 原始文本
+// 返回值: 切分后的文本
+func SegmentSearchText(text string) string {
+	var sb strings.Builder
+	sb.Grow(len(text) * 2)
+	for _, r := range text {
+		if isCJK(r) {
+			sb.WriteByte(' ')
+			sb.WriteRune(r)
+			sb.WriteByte(' ')
+		} else {
+			sb.WriteRune(r)
+		}
+	}
+	return sb.String()
+}
+
+// SearchTerms 将用户输入的查询拆分为检索词
+// 参数: query - 查询字符串
+// 返回值: 去重后的检索词（最多 8 个）
+func SearchTerms(query string) []string {
+	seen := make(map[string]bool)
+	var terms []string
+	for _, field := range strings.Fields(query) {
+		term := strings.Trim(field, `"'+-*()<>~@`)
+		key := strings.ToLower(term)
+		if term == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		terms = append(terms, term)
+		if len(terms) == 8 {
+			break
+		}
+	}
+	return terms
+}
+
+// Highlight 对文本进行 HTML 转义并用 <mark> 标记检索词
+// 参数: text - 原始文本, terms - 检索词
+// 返回值: 高亮后的 HTML 片段
+func Highlight(text string, terms []string) string {
+	lower := strings.ToLower(text)
+	var sb strings.Builder
+	for i := 0; i < len(text); {
+		matched := 0
+		for _, term := range terms {
+			t := strings.ToLower(term)
+			if t != "" && strings.HasPrefix(lower[i:], t) && len(t) > matched {
+				matched = len(t)
+			}
+		}
+		if matched > 0 && len(lower) == len(text) {
+			sb.WriteString("<mark>")
+			sb.WriteString(html.EscapeString(text[i : i+matched]))
+			sb.WriteString("</mark>")
+			i += matched
+			continue
+		}
+		_, size := utf8.DecodeRuneInString(text[i:])
+		sb.WriteString(html.EscapeString(text[i : i+size]))
+		i += size
+	}
+	return sb.String()
+}
+
+// Snippet 截取包含首个检索词的文本片段并高亮
+// 参数: text - 原始文本, terms - 检索词, width - 片段长度（字符数）
+// 返回值: 高亮后的 HTML 片段
+func Snippet(text string, terms []string, width int) string {
+	runes := []rune(strings.Join(strings.Fields(text), " "))
+	lower := []rune(strings.ToLower(string(runes)))
+
+	// 定位第一个出现的检索词
+	start := -1
+	if len(lower) == len(runes) {
+		for _, term := range terms {
+			if idx := indexRunes(lower, []rune(strings.ToLower(term))); idx >= 0 && (start < 0 || idx < start) {
+				start = idx
+			}
+		}
+	}
+	if start < 0 {
+		start = 0
+	}
+
+	// 让检索词出现在片段前部，保留少量上文
+	begin := start - width/4
+	if begin < 0 {
+		begin = 0
+	}
+	end := begin + width
+	if end > len(runes) {
+		end = len(runes)
+	}
+
+	snippet := Highlight(string(runes[begin:end]), terms)
+	if begin > 0 {
+		snippet = "…" + snippet
+	}
+	if end < len(runes) {
+		snippet += "…"
+	}
+	return snippet
+}
+
+// indexRunes 在 rune 切片中查找子串位置
+func indexRunes(haystack, needle []rune) int {
+	if len(needle) == 0 {
+		return -1
+	}
+	for i := 0; i+len(needle) <= len(haystack); i++ {
+		match := true
+		for j := range needle {
+			if haystack[i+j] != needle[j] {
+				match = false
+				break
+			}
+		}
+		if match {
+			return i
+		}
+	}
+	return -1
+}
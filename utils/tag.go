@@ -0,0 +1,11 @@
+package utils
+
+import "strings"
+
+// NormalizeTagName 规范化标签名
+// 去除首尾空白、转为小写并将连续空白合并为单个连字符，使 "Go"、" go " 视为同一标签
+// 参数: name - 原始标签名
+// 返回值: 规范化后的标签名
+func NormalizeTagName(name string) string {
+	return strings.Join(strings.Fields(strings.ToLower(name)), "-")
+}